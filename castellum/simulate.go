@@ -0,0 +1,313 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package castellum
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	. "go.xyrillian.de/gg/option"
+)
+
+// AssetObservation is a single observation of an asset's size and usage, as used by SimulateResizes.
+type AssetObservation struct {
+	At   time.Time
+	Size uint64
+	// Unlike in type Asset, usage values are given in absolute terms (not in percent), in the same unit as Size.
+	Usage UsageValues
+}
+
+// SimulateResizes simulates which resize operations Castellum would perform
+// for an asset with the given resource configuration, given a time series of
+// observations of the asset's size and usage. This can be used to dry-run an
+// autoscaling configuration before enabling it.
+//
+// The asset is checked once at each observation, and once more at `now`
+// using the usage values of the last observation (unless `now` is before
+// that, in which case observations after `now` are ignored). Observations
+// must be sorted by time. At each check:
+//
+//   - If the asset crosses a threshold (with critical taking precedence over
+//     high, and high taking precedence over low), and the size can be changed
+//     in the respective direction, an operation is created.
+//   - While the reason of a pending operation still applies, its NewSize is
+//     recomputed from the current usage.
+//   - If a pending operation for the high threshold finds the critical
+//     threshold crossed, it is escalated: its Reason and NewSize are updated,
+//     and it becomes subject to the delay of the critical threshold (counting
+//     from its original creation).
+//   - Otherwise, a pending operation is cancelled if its reason does not apply anymore.
+//   - A pending operation is confirmed once the delay of its threshold has
+//     elapsed since its creation. Since the simulation cannot observe the
+//     actual resize, it is assumed that the operation is greenlit, executed
+//     and finished successfully at that same moment. The asset's size
+//     changes to the operation's NewSize for all following checks.
+//
+// If the Size of an observation differs from that of the previous
+// observation, this is taken to be a resize outside of Castellum. Otherwise,
+// the observed size is ignored in favor of the size resulting from the
+// simulated operations.
+//
+// The result contains all operations in order of creation. Only the last
+// operation can still be pending.
+func SimulateResizes(res Resource, observations []AssetObservation, now time.Time) ([]Operation, error) {
+	if res.SizeSteps.Percent <= 0 && !res.SizeSteps.Single {
+		return nil, errors.New("cannot simulate resizes without size steps: either SizeSteps.Percent or SizeSteps.Single must be set")
+	}
+	if !slices.IsSortedFunc(observations, func(lhs, rhs AssetObservation) int { return lhs.At.Compare(rhs.At) }) {
+		return nil, errors.New("cannot simulate resizes: observations are not sorted by time")
+	}
+
+	s := resizeSimulation{Resource: res}
+	var last Option[AssetObservation]
+	for _, o := range observations {
+		if o.At.After(now) {
+			break
+		}
+		s.Check(o)
+		last = Some(o)
+	}
+	if o, ok := last.Unpack(); ok && now.After(o.At) {
+		o.At = now
+		s.Check(o)
+	}
+
+	if op, ok := s.PendingOperation.Unpack(); ok {
+		return append(s.FinishedOperations, op), nil
+	}
+	return s.FinishedOperations, nil
+}
+
+// resizeSimulation holds the state of SimulateResizes between checks.
+type resizeSimulation struct {
+	Resource           Resource
+	Size               uint64
+	LastObservedSize   Option[uint64]
+	PendingOperation   Option[Operation]
+	FinishedOperations []Operation
+}
+
+// Check implements a single check of the asset within SimulateResizes.
+func (s *resizeSimulation) Check(o AssetObservation) {
+	if s.LastObservedSize != Some(o.Size) {
+		s.Size = o.Size
+	}
+	s.LastObservedSize = Some(o.Size)
+	atUnix := o.At.Unix()
+	reason, newSize, eligible := s.findEligibleOperation(o.Usage)
+
+	// update pending operation if its reason still applies (or if it escalates from high to critical),
+	// otherwise cancel it
+	if op, ok := s.PendingOperation.Unpack(); ok {
+		switch {
+		case eligible && op.Reason == reason:
+			op.NewSize = newSize
+			s.PendingOperation = Some(op)
+		case eligible && op.Reason == OperationReasonHigh && reason == OperationReasonCritical:
+			op.Reason = reason
+			op.NewSize = newSize
+			s.PendingOperation = Some(op)
+		default:
+			op.State = OperationStateCancelled
+			op.Finished = Some(OperationFinish{AtUnix: atUnix})
+			s.FinishedOperations = append(s.FinishedOperations, op)
+			s.PendingOperation = None[Operation]()
+		}
+	}
+
+	// create new operation if necessary
+	if s.PendingOperation.IsNone() && eligible {
+		usagePercent := make(UsageValues, len(o.Usage))
+		for metric, usage := range o.Usage {
+			usagePercent[metric] = getUsagePercent(s.Size, usage)
+		}
+		s.PendingOperation = Some(Operation{
+			State:   OperationStateCreated,
+			Reason:  reason,
+			OldSize: s.Size,
+			NewSize: newSize,
+			Created: OperationCreation{AtUnix: atUnix, UsagePercent: usagePercent},
+		})
+	}
+
+	// confirm pending operation once its delay has elapsed (and then assume that it is executed immediately)
+	if op, ok := s.PendingOperation.Unpack(); ok {
+		delaySeconds := int64(s.threshold(op.Reason).UnwrapOr(Threshold{}).DelaySeconds)
+		if atUnix >= op.Created.AtUnix+delaySeconds {
+			op.State = OperationStateSucceeded
+			op.Confirmed = Some(OperationConfirmation{AtUnix: atUnix})
+			op.Greenlit = Some(OperationGreenlight{AtUnix: atUnix})
+			op.Finished = Some(OperationFinish{AtUnix: atUnix})
+			s.FinishedOperations = append(s.FinishedOperations, op)
+			s.PendingOperation = None[Operation]()
+			s.Size = op.NewSize
+		}
+	}
+}
+
+func (s *resizeSimulation) threshold(reason OperationReason) Option[Threshold] {
+	switch reason {
+	case OperationReasonLow:
+		return s.Resource.LowThreshold
+	case OperationReasonHigh:
+		return s.Resource.HighThreshold
+	case OperationReasonCritical:
+		return s.Resource.CriticalThreshold
+	default:
+		return None[Threshold]()
+	}
+}
+
+// Returns the reason and target size of the operation that applies to the
+// asset at its current size, or false if no operation applies.
+func (s *resizeSimulation) findEligibleOperation(usage UsageValues) (OperationReason, uint64, bool) {
+	for _, reason := range []OperationReason{OperationReasonCritical, OperationReasonHigh, OperationReasonLow} {
+		if !s.matchesReason(reason, s.Size, usage) {
+			continue
+		}
+		newSize := s.getNewSize(reason, usage)
+		if reason == OperationReasonLow && newSize < s.Size {
+			return reason, newSize, true
+		}
+		if reason != OperationReasonLow && newSize > s.Size {
+			return reason, newSize, true
+		}
+	}
+	return "", 0, false
+}
+
+// Returns whether an asset of the given size crosses the threshold for the given reason.
+//
+// The low threshold is crossed if the usage is below the threshold for all
+// metrics. The high and critical thresholds are crossed if the usage is at
+// or above the threshold for any metric. Furthermore, if the free space is
+// below MinimumFree for any metric, this counts as crossing the high
+// threshold (or the critical threshold if MinimumFreeIsCritical is set).
+func (s *resizeSimulation) matchesReason(reason OperationReason, size uint64, usage UsageValues) bool {
+	threshold, hasThreshold := s.threshold(reason).Unpack()
+	if reason == OperationReasonLow {
+		if !hasThreshold || len(threshold.UsagePercent) == 0 {
+			return false
+		}
+		for metric, percent := range threshold.UsagePercent {
+			if getUsagePercent(size, usage[metric]) >= percent {
+				return false
+			}
+		}
+		return true
+	}
+
+	if hasThreshold {
+		for metric, percent := range threshold.UsagePercent {
+			if getUsagePercent(size, usage[metric]) >= percent {
+				return true
+			}
+		}
+	}
+	constraints := s.Resource.SizeConstraints.UnwrapOr(SizeConstraints{})
+	if minFree, ok := constraints.MinimumFree.Unpack(); ok && constraints.MinimumFreeIsCritical == (reason == OperationReasonCritical) {
+		for _, value := range usage {
+			if float64(size)-value < float64(minFree) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the target size for an operation with the given reason, before checking whether it is a valid resize.
+func (s *resizeSimulation) getNewSize(reason OperationReason, usage UsageValues) uint64 {
+	var newSize uint64
+	switch {
+	case s.Resource.SizeSteps.Single:
+		// resize in a single step, such that the threshold in question is not crossed anymore
+		if reason == OperationReasonLow {
+			for metric, percent := range s.Resource.LowThreshold.UnwrapOr(Threshold{}).UsagePercent {
+				if percent > 0 {
+					newSize = max(newSize, uint64(math.Floor(100*usage[metric]/percent)))
+				}
+			}
+		} else {
+			// when leaving the critical threshold, we also want to leave the high threshold if there is one
+			threshold := s.Resource.HighThreshold.UnwrapOr(s.Resource.CriticalThreshold.UnwrapOr(Threshold{}))
+			newSize = getSmallestSizeBelowThreshold(threshold, usage)
+		}
+
+	case reason == OperationReasonLow:
+		newSize = s.Size - min(s.getPercentStep(s.Size), s.Size)
+
+	case reason == OperationReasonHigh:
+		newSize = addSaturating(s.Size, s.getPercentStep(s.Size))
+
+	case reason == OperationReasonCritical:
+		// take as many steps as necessary to leave the critical threshold (or until we run into the maximum size);
+		// like for single-step resizing, we also want to leave the high threshold if there is one
+		targetReason := OperationReasonCritical
+		if s.Resource.HighThreshold.IsSome() {
+			targetReason = OperationReasonHigh
+		}
+		maxSize := s.Resource.SizeConstraints.UnwrapOr(SizeConstraints{}).Maximum.UnwrapOr(math.MaxUint64)
+		newSize = addSaturating(s.Size, s.getPercentStep(s.Size))
+		for newSize < maxSize && s.matchesReason(targetReason, newSize, usage) {
+			newSize = addSaturating(newSize, s.getPercentStep(newSize))
+		}
+	}
+
+	// when downsizing, do not go so far that the high threshold would be crossed
+	if reason == OperationReasonLow {
+		if threshold, ok := s.Resource.HighThreshold.Unpack(); ok {
+			newSize = max(newSize, getSmallestSizeBelowThreshold(threshold, usage))
+		}
+	}
+
+	// apply size constraints
+	constraints := s.Resource.SizeConstraints.UnwrapOr(SizeConstraints{})
+	if minFree, ok := constraints.MinimumFree.Unpack(); ok {
+		for _, value := range usage {
+			newSize = max(newSize, addSaturating(uint64(math.Ceil(value)), minFree))
+		}
+	}
+	if minSize, ok := constraints.Minimum.Unpack(); ok {
+		newSize = max(newSize, minSize)
+	}
+	if maxSize, ok := constraints.Maximum.Unpack(); ok {
+		newSize = min(newSize, maxSize)
+	}
+	return newSize
+}
+
+// Returns the size of a single percentage step starting from the given size. This is always at least 1.
+func (s *resizeSimulation) getPercentStep(size uint64) uint64 {
+	return max(uint64(math.Floor(float64(size)*s.Resource.SizeSteps.Percent/100)), 1)
+}
+
+// Returns the smallest size at which the usage is below the given threshold for all metrics.
+func getSmallestSizeBelowThreshold(threshold Threshold, usage UsageValues) uint64 {
+	var result uint64
+	for metric, percent := range threshold.UsagePercent {
+		if percent > 0 {
+			result = max(result, uint64(math.Floor(100*usage[metric]/percent))+1)
+		}
+	}
+	return result
+}
+
+func getUsagePercent(size uint64, usage float64) float64 {
+	if size == 0 {
+		if usage == 0 {
+			return 0
+		}
+		return math.Inf(+1)
+	}
+	return 100 * usage / float64(size)
+}
+
+func addSaturating(a, b uint64) uint64 {
+	if b > math.MaxUint64-a {
+		return math.MaxUint64
+	}
+	return a + b
+}
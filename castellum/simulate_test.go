@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package castellum
+
+import (
+	"testing"
+	"time"
+
+	. "go.xyrillian.de/gg/option"
+)
+
+var simulationStart = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func makeObservation(offsetSeconds int64, size uint64, usage float64) AssetObservation {
+	return AssetObservation{
+		At:    simulationStart.Add(time.Duration(offsetSeconds) * time.Second),
+		Size:  size,
+		Usage: UsageValues{SingularUsageMetric: usage},
+	}
+}
+
+func makeSucceededOperation(reason OperationReason, oldSize, newSize uint64, usagePercent float64, createdAt, finishedAt int64) Operation {
+	return Operation{
+		State:     OperationStateSucceeded,
+		Reason:    reason,
+		OldSize:   oldSize,
+		NewSize:   newSize,
+		Created:   OperationCreation{AtUnix: simulationStart.Unix() + createdAt, UsagePercent: UsageValues{SingularUsageMetric: usagePercent}},
+		Confirmed: Some(OperationConfirmation{AtUnix: simulationStart.Unix() + finishedAt}),
+		Greenlit:  Some(OperationGreenlight{AtUnix: simulationStart.Unix() + finishedAt}),
+		Finished:  Some(OperationFinish{AtUnix: simulationStart.Unix() + finishedAt}),
+	}
+}
+
+func TestSimulateResizesWithPercentSteps(t *testing.T) {
+	res := Resource{
+		LowThreshold:      Some(Threshold{UsagePercent: UsageValues{SingularUsageMetric: 20}, DelaySeconds: 120}),
+		HighThreshold:     Some(Threshold{UsagePercent: UsageValues{SingularUsageMetric: 80}, DelaySeconds: 60}),
+		CriticalThreshold: Some(Threshold{UsagePercent: UsageValues{SingularUsageMetric: 95}}),
+		SizeConstraints:   Some(SizeConstraints{Minimum: Some[uint64](100)}),
+		SizeSteps:         SizeSteps{Percent: 10},
+	}
+	observations := []AssetObservation{
+		makeObservation(0, 1000, 500),    // nothing to do
+		makeObservation(30, 1000, 850),   // high threshold crossed
+		makeObservation(60, 1000, 700),   // high threshold not crossed anymore -> cancel
+		makeObservation(90, 1000, 850),   // high threshold crossed again
+		makeObservation(150, 1000, 850),  // delay has elapsed -> upsize to 1100
+		makeObservation(180, 1000, 1078), // critical threshold crossed -> immediate upsize
+		makeObservation(190, 1000, 1078), // no further upsize since the critical upsize also left the high threshold
+		makeObservation(200, 1000, 121),
+	}
+
+	cancelledOperation := Operation{
+		State:    OperationStateCancelled,
+		Reason:   OperationReasonHigh,
+		OldSize:  1000,
+		NewSize:  1100,
+		Created:  OperationCreation{AtUnix: simulationStart.Unix() + 30, UsagePercent: UsageValues{SingularUsageMetric: 85}},
+		Finished: Some(OperationFinish{AtUnix: simulationStart.Unix() + 60}),
+	}
+	expected := []Operation{
+		cancelledOperation,
+		makeSucceededOperation(OperationReasonHigh, 1000, 1100, 85, 90, 150),
+		// critical threshold has no delay; multiple steps are taken to leave the critical and high thresholds
+		// (1100 -> 1210 -> 1331 -> 1464, since usage is still above 80% at 1331)
+		makeSucceededOperation(OperationReasonCritical, 1100, 1464, 98, 180, 180),
+		// low threshold delay elapses during the final check at `now`
+		makeSucceededOperation(OperationReasonLow, 1464, 1318, 100*121.0/1464, 200, 400),
+	}
+	actual, err := SimulateResizes(res, observations, simulationStart.Add(400*time.Second))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	checkDeepEqual(t, "operations", actual, expected)
+
+	// if `now` is earlier, the last operation is still pending
+	pendingOperation := expected[3]
+	pendingOperation.State = OperationStateCreated
+	pendingOperation.Confirmed = None[OperationConfirmation]()
+	pendingOperation.Greenlit = None[OperationGreenlight]()
+	pendingOperation.Finished = None[OperationFinish]()
+	expected[3] = pendingOperation
+	actual, err = SimulateResizes(res, observations, simulationStart.Add(300*time.Second))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	checkDeepEqual(t, "operations", actual, expected)
+
+	// observations after `now` are ignored
+	actual, err = SimulateResizes(res, observations, simulationStart.Add(100*time.Second))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected = []Operation{cancelledOperation, {
+		State:   OperationStateCreated,
+		Reason:  OperationReasonHigh,
+		OldSize: 1000,
+		NewSize: 1100,
+		Created: OperationCreation{AtUnix: simulationStart.Unix() + 90, UsagePercent: UsageValues{SingularUsageMetric: 85}},
+	}}
+	checkDeepEqual(t, "operations", actual, expected)
+}
+
+func TestSimulateResizesWithSingleStepAndMinimumFree(t *testing.T) {
+	res := Resource{
+		HighThreshold: Some(Threshold{UsagePercent: UsageValues{SingularUsageMetric: 80}, DelaySeconds: 60}),
+		SizeConstraints: Some(SizeConstraints{
+			Maximum:               Some[uint64](1500),
+			MinimumFree:           Some[uint64](300),
+			MinimumFreeIsCritical: true,
+		}),
+		SizeSteps: SizeSteps{Single: true},
+	}
+	observations := []AssetObservation{
+		makeObservation(0, 1000, 600),  // nothing to do
+		makeObservation(10, 1000, 750), // less than MinimumFree -> immediate upsize, enough to have MinimumFree
+		makeObservation(20, 1000, 1300),
+		makeObservation(30, 1000, 1400), // cannot grow beyond maximum size
+		makeObservation(40, 1200, 1000), // size changed outside of Castellum
+	}
+	expected := []Operation{
+		makeSucceededOperation(OperationReasonCritical, 1000, 1050, 75, 10, 10),
+		// single step is large enough to leave the high threshold, but is capped by the maximum size
+		makeSucceededOperation(OperationReasonCritical, 1050, 1500, 100*1300.0/1050, 20, 20),
+		makeSucceededOperation(OperationReasonCritical, 1200, 1300, 100*1000.0/1200, 40, 40),
+	}
+	actual, err := SimulateResizes(res, observations, simulationStart.Add(40*time.Second))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	checkDeepEqual(t, "operations", actual, expected)
+}
+
+func TestSimulateResizesUpdatesPendingOperation(t *testing.T) {
+	res := Resource{
+		HighThreshold:     Some(Threshold{UsagePercent: UsageValues{SingularUsageMetric: 80}, DelaySeconds: 60}),
+		CriticalThreshold: Some(Threshold{UsagePercent: UsageValues{SingularUsageMetric: 95}, DelaySeconds: 30}),
+		SizeSteps:         SizeSteps{Single: true},
+	}
+
+	// usage keeps rising during the delay of the high threshold -> the target size follows the usage
+	observations := []AssetObservation{
+		makeObservation(0, 1000, 850),  // high threshold crossed -> target size 1063
+		makeObservation(30, 1000, 900), // still high, but more usage -> target size 1126
+		makeObservation(60, 1000, 920), // delay has elapsed -> upsize to 1151
+	}
+	expected := []Operation{
+		makeSucceededOperation(OperationReasonHigh, 1000, 1151, 85, 0, 60),
+	}
+	actual, err := SimulateResizes(res, observations, simulationStart.Add(60*time.Second))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	checkDeepEqual(t, "operations", actual, expected)
+
+	// usage crosses the critical threshold during the delay of the high threshold -> the operation is escalated
+	observations = []AssetObservation{
+		makeObservation(0, 1000, 850),  // high threshold crossed -> target size 1063
+		makeObservation(20, 1000, 960), // critical threshold crossed -> escalate, target size 1201
+		makeObservation(40, 1000, 970), // delay of critical threshold has elapsed -> upsize to 1213
+	}
+	expected = []Operation{
+		makeSucceededOperation(OperationReasonCritical, 1000, 1213, 85, 0, 40),
+	}
+	actual, err = SimulateResizes(res, observations, simulationStart.Add(40*time.Second))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	checkDeepEqual(t, "operations", actual, expected)
+
+	// before the delay has elapsed, the escalated operation is still pending
+	actual, err = SimulateResizes(res, observations[:2], simulationStart.Add(20*time.Second))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected = []Operation{{
+		State:   OperationStateCreated,
+		Reason:  OperationReasonCritical,
+		OldSize: 1000,
+		NewSize: 1201,
+		Created: OperationCreation{AtUnix: simulationStart.Unix(), UsagePercent: UsageValues{SingularUsageMetric: 85}},
+	}}
+	checkDeepEqual(t, "operations", actual, expected)
+}
+
+func TestSimulateResizesErrors(t *testing.T) {
+	res := Resource{
+		HighThreshold: Some(Threshold{UsagePercent: UsageValues{SingularUsageMetric: 80}}),
+	}
+	_, err := SimulateResizes(res, nil, simulationStart)
+	expectedMessage := "cannot simulate resizes without size steps: either SizeSteps.Percent or SizeSteps.Single must be set"
+	if err == nil || err.Error() != expectedMessage {
+		t.Errorf("expected error %q, but got %v", expectedMessage, err)
+	}
+
+	res.SizeSteps = SizeSteps{Percent: 10}
+	observations := []AssetObservation{makeObservation(10, 1000, 500), makeObservation(0, 1000, 500)}
+	_, err = SimulateResizes(res, observations, simulationStart)
+	expectedMessage = "cannot simulate resizes: observations are not sorted by time"
+	if err == nil || err.Error() != expectedMessage {
+		t.Errorf("expected error %q, but got %v", expectedMessage, err)
+	}
+}
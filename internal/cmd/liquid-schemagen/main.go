@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Command liquid-schemagen writes the JSON schemas and the OpenAPI document for the LIQUID API into the "schemas" directory.
+// It is invoked by `go generate` in the directory of package liquid.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sapcc/go-api-declarations/internal/liquidschema"
+)
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "liquid-schemagen: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	files, err := liquidschema.Generate(".")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll("schemas", 0o777)
+	if err != nil {
+		return err
+	}
+	for name, contents := range files {
+		err := os.WriteFile(filepath.Join("schemas", name), contents, 0o666)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
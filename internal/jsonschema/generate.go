@@ -0,0 +1,284 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	. "go.xyrillian.de/gg/option"
+)
+
+// Generator derives JSON schemas from Go types through reflection,
+// following the same rules that encoding/json uses for serialization.
+//
+// All named types declared outside of the standard library are placed in the $defs section of the generated schema.
+// Types with custom JSON serialization (e.g. through a MarshalJSON method) must have an entry in CustomSchemas.
+type Generator struct {
+	// Schemas for types whose serialization cannot be derived through reflection, or that have additional constraints.
+	CustomSchemas map[reflect.Type]*Schema
+	// Permissible values for types that are used as enums.
+	EnumValues map[reflect.Type][]any
+	// If not nil, this is used to fill the descriptions of named types (if fieldName is empty) or of struct fields.
+	Describe func(t reflect.Type, fieldName string) string
+	// The prefix for references to named types. If empty, "#/$defs/" is used.
+	// This only needs to be set when using GenerateDefs.
+	RefPrefix string
+}
+
+// Generate builds a root schema document for the given type.
+func (g Generator) Generate(t reflect.Type) (*Schema, error) {
+	s := newGeneratorState(g)
+	root, err := s.schemaFor(t)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{
+		Schema: DraftURL,
+		Ref:    root.Ref,
+		Title:  t.Name(),
+		Defs:   s.defs,
+	}, nil
+}
+
+// GenerateDefs builds the schemas for the given named types, as well as for all named types referenced by them.
+// This is intended for documents that embed JSON schemas in a different structure, e.g. OpenAPI documents.
+func (g Generator) GenerateDefs(types ...reflect.Type) (map[string]*Schema, error) {
+	s := newGeneratorState(g)
+	for _, t := range types {
+		if !isDefinedOutsideStdlib(t) {
+			return nil, fmt.Errorf("cannot generate $defs entry for %s because it is not a named type outside of the standard library", t)
+		}
+		_, err := s.schemaFor(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.defs, nil
+}
+
+type generatorState struct {
+	g        Generator
+	defs     map[string]*Schema
+	defTypes map[string]reflect.Type
+}
+
+func newGeneratorState(g Generator) *generatorState {
+	if g.RefPrefix == "" {
+		g.RefPrefix = "#/$defs/"
+	}
+	return &generatorState{g: g, defs: make(map[string]*Schema), defTypes: make(map[string]reflect.Type)}
+}
+
+var (
+	bigIntType        = reflect.TypeFor[big.Int]()
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+func (s *generatorState) schemaFor(t reflect.Type) (*Schema, error) {
+	// types with special handling
+	switch t {
+	case bigIntType:
+		return &Schema{Type: "integer"}, nil
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case rawMessageType:
+		return &Schema{}, nil
+	}
+	if t.Kind() == reflect.Pointer {
+		return s.schemaFor(t.Elem())
+	}
+	if innerType, ok := optionInnerType(t); ok {
+		inner, err := s.schemaFor(innerType)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{AnyOf: []*Schema{inner, {Type: "null"}}}, nil
+	}
+
+	if !isDefinedOutsideStdlib(t) {
+		return s.buildSchema(t)
+	}
+
+	// named types go into $defs
+	name := t.Name()
+	if other, exists := s.defTypes[name]; exists {
+		if other != t {
+			return nil, fmt.Errorf("cannot generate $defs entry for %s because it has the same name as %s", t, other)
+		}
+		return &Schema{Ref: s.g.RefPrefix + name}, nil
+	}
+	s.defTypes[name] = t
+	s.defs[name] = &Schema{} // placeholder to support recursive types
+
+	def, err := s.buildSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	if s.g.Describe != nil && def.Description == "" {
+		def.Description = s.g.Describe(t, "")
+	}
+	s.defs[name] = def
+	return &Schema{Ref: s.g.RefPrefix + name}, nil
+}
+
+// buildSchema builds the schema for a type that is not special-cased in schemaFor.
+func (s *generatorState) buildSchema(t reflect.Type) (*Schema, error) {
+	if custom, exists := s.g.CustomSchemas[t]; exists {
+		cloned := *custom
+		return &cloned, nil
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return nil, fmt.Errorf("type %s has custom serialization, so it needs an entry in Generator.CustomSchemas", t)
+	}
+
+	var result *Schema
+	switch t.Kind() {
+	case reflect.Bool:
+		result = &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result = &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result = &Schema{Type: "integer", Minimum: Some[int64](0)}
+	case reflect.Float32, reflect.Float64:
+		result = &Schema{Type: "number"}
+	case reflect.String:
+		result = &Schema{Type: "string"}
+	case reflect.Interface:
+		result = &Schema{}
+	case reflect.Slice, reflect.Array:
+		items, err := s.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		result = &Schema{Type: "array", Items: items}
+	case reflect.Map:
+		return s.buildMapSchema(t)
+	case reflect.Struct:
+		return s.buildStructSchema(t)
+	default:
+		return nil, fmt.Errorf("cannot generate schema for type %s of kind %s", t, t.Kind())
+	}
+
+	if values, exists := s.g.EnumValues[t]; exists {
+		result.Enum = values
+	}
+	return result, nil
+}
+
+func (s *generatorState) buildMapSchema(t reflect.Type) (*Schema, error) {
+	if t.Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("cannot generate schema for map type %s with non-string keys", t)
+	}
+	values, err := s.schemaFor(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	result := &Schema{Type: "object", AdditionalProperties: values}
+	if isDefinedOutsideStdlib(t.Key()) {
+		result.PropertyNames, err = s.schemaFor(t.Key())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *generatorState) buildStructSchema(t reflect.Type) (*Schema, error) {
+	result := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	err := s.addStructFields(result, t)
+	return result, err
+}
+
+func (s *generatorState) addStructFields(result *Schema, t reflect.Type) error {
+	for idx := range t.NumField() {
+		field := t.Field(idx)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			err := s.addStructFields(result, field.Type)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		var isOptional bool
+		for opt := range strings.SplitSeq(opts, ",") {
+			switch opt {
+			case "omitempty", "omitzero":
+				isOptional = true
+			case "string":
+				return fmt.Errorf("cannot generate schema for field %s.%s with option %q", t, field.Name, opt)
+			}
+		}
+
+		fieldSchema, err := s.schemaFor(field.Type)
+		if err != nil {
+			return err
+		}
+		if !isOptional && isNullable(field.Type) {
+			// encoding/json renders nil pointers, maps and slices as null
+			fieldSchema = &Schema{AnyOf: []*Schema{fieldSchema, {Type: "null"}}}
+		}
+		if s.g.Describe != nil {
+			fieldSchema.Description = s.g.Describe(t, field.Name)
+		}
+		result.Properties[name] = fieldSchema
+		if !isOptional {
+			result.Required = append(result.Required, name)
+		}
+	}
+	return nil
+}
+
+func isNullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Map:
+		return true
+	case reflect.Slice:
+		return t != rawMessageType
+	default:
+		return false
+	}
+}
+
+// optionInnerType recognizes types of the form Option[T] from go.xyrillian.de/gg/option, and returns T.
+func optionInnerType(t reflect.Type) (reflect.Type, bool) {
+	if t.PkgPath() != "go.xyrillian.de/gg/option" || !strings.HasPrefix(t.Name(), "Option[") {
+		return nil, false
+	}
+	method, ok := t.MethodByName("Unpack")
+	if !ok {
+		return nil, false
+	}
+	return method.Type.Out(0), true
+}
+
+// isDefinedOutsideStdlib returns whether the given type is a named type declared outside of the standard library.
+func isDefinedOutsideStdlib(t reflect.Type) bool {
+	firstPathElement, _, _ := strings.Cut(t.PkgPath(), "/")
+	return t.Name() != "" && strings.Contains(firstPathElement, ".")
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+)
+
+type testColor string
+
+type testEmbedded struct {
+	Weight float64 `json:"weight"`
+}
+
+type testPayload struct {
+	testEmbedded
+	Name    string            `json:"name"`
+	Count   uint32            `json:"count,omitempty"`
+	Color   Option[testColor] `json:"color,omitzero"`
+	Tags    []string          `json:"tags"`
+	Since   time.Time         `json:"since"`
+	Ignored int               `json:"-"`
+}
+
+type testCustomSerialization struct{}
+
+func (testCustomSerialization) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+func TestGenerate(t *testing.T) {
+	g := Generator{EnumValues: map[reflect.Type][]any{reflect.TypeFor[testColor](): {"red", "green"}}}
+	schema, err := g.Generate(reflect.TypeFor[testPayload]())
+	assert.ErrEqual(t, err, nil)
+
+	buf, err := json.Marshal(schema)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(buf), `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/testPayload","title":"testPayload","$defs":{`+
+		`"testColor":{"type":"string","enum":["red","green"]},`+
+		`"testPayload":{"type":"object","properties":{`+
+		`"color":{"anyOf":[{"$ref":"#/$defs/testColor"},{"type":"null"}]},`+
+		`"count":{"type":"integer","minimum":0},`+
+		`"name":{"type":"string"},`+
+		`"since":{"type":"string","format":"date-time"},`+
+		`"tags":{"anyOf":[{"type":"array","items":{"type":"string"}},{"type":"null"}]},`+
+		`"weight":{"type":"number"}},`+
+		`"required":["weight","name","tags","since"]}}}`)
+
+	assert.ErrEqual(t, schema.Validate([]byte(`{"weight":1.5,"name":"foo","color":"red","tags":null,"since":"2026-01-01T00:00:00Z"}`)), nil)
+	assert.ErrEqual(t, schema.Validate([]byte(`{"weight":1.5,"name":"foo","color":"blue","tags":[42],"since":"2026-01-01T00:00:00Z"}`)),
+		`document does not conform to schema: at /color: expected one of "red", "green", but got "blue"; at /tags/0: expected a value of type "string", but got 42`)
+	assert.ErrEqual(t, schema.Validate([]byte(`[`)), "cannot parse JSON document: unexpected EOF")
+
+	// types with custom serialization need to be described explicitly
+	_, err = g.Generate(reflect.TypeFor[testCustomSerialization]())
+	assert.ErrEqual(t, err, "type jsonschema.testCustomSerialization has custom serialization, so it needs an entry in Generator.CustomSchemas")
+	g.CustomSchemas = map[reflect.Type]*Schema{reflect.TypeFor[testCustomSerialization](): {Type: "string"}}
+	_, err = g.Generate(reflect.TypeFor[testCustomSerialization]())
+	assert.ErrEqual(t, err, nil)
+}
@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jsonschema contains a minimal implementation of JSON Schema (draft 2020-12).
+// It covers only those parts of the specification that are needed to describe the payload types of our APIs:
+// a [Generator] that derives schemas from Go types through reflection, and a validator for the resulting schemas.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/go-api-declarations/internal/errorset"
+)
+
+// DraftURL identifies the version of JSON Schema that is implemented by this package.
+const DraftURL = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document, or a subschema within such a document.
+// Only the keywords listed here are supported.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type      string        `json:"type,omitempty"`
+	Enum      []any         `json:"enum,omitempty"`
+	Format    string        `json:"format,omitempty"`
+	Pattern   string        `json:"pattern,omitempty"`
+	MinLength Option[int]   `json:"minLength,omitzero"`
+	Minimum   Option[int64] `json:"minimum,omitzero"`
+	Not       *Schema       `json:"not,omitempty"`
+	AnyOf     []*Schema     `json:"anyOf,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Validate checks whether the given JSON document conforms to this schema.
+// This must be called on a root schema, since references are resolved against its Defs.
+func (s *Schema) Validate(document []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(document))
+	dec.UseNumber()
+	var value any
+	err := dec.Decode(&value)
+	if err != nil {
+		return fmt.Errorf("cannot parse JSON document: %w", err)
+	}
+
+	v := validator{root: s}
+	v.validate(s, value, "")
+	if !v.errs.IsEmpty() {
+		return fmt.Errorf("document does not conform to schema: %s", v.errs.Join("; "))
+	}
+	return nil
+}
+
+type validator struct {
+	root *Schema
+	errs errorset.ErrorSet
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	if path == "" {
+		path = "/"
+	}
+	v.errs.Addf("at %s: %s", path, fmt.Sprintf(format, args...))
+}
+
+// validate checks `value` against `s` and reports all errors into v.errs.
+// `path` is a JSON pointer to the value within the document.
+func (v *validator) validate(s *Schema, value any, path string) {
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+		target := v.root.Defs[name]
+		if !ok || target == nil {
+			v.addf(path, "cannot resolve reference %q", s.Ref)
+			return
+		}
+		v.validate(target, value, path)
+	}
+
+	if s.Type != "" && !matchesType(value, s.Type) {
+		v.addf(path, "expected a value of type %q, but got %s", s.Type, describeValue(value))
+		return // no further checks if the type is already wrong
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(candidate any) bool { return jsonEqual(candidate, value) }) {
+		v.addf(path, "expected one of %s, but got %s", formatJSONList(s.Enum), describeValue(value))
+	}
+	if s.Not != nil && v.isValid(s.Not, value) {
+		v.addf(path, "%s is not acceptable here", describeValue(value))
+	}
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(sub *Schema) bool { return v.isValid(sub, value) }) {
+		// report the errors for the most likely candidate (the first one with matching type)
+		idx := slices.IndexFunc(s.AnyOf, func(sub *Schema) bool { return matchesType(value, v.resolve(sub).Type) })
+		if idx >= 0 {
+			v.validate(s.AnyOf[idx], value, path)
+		} else {
+			typeNames := make([]string, len(s.AnyOf))
+			for idx, sub := range s.AnyOf {
+				typeNames[idx] = fmt.Sprintf("%q", v.resolve(sub).Type)
+			}
+			v.addf(path, "expected a value of type %s, but got %s", strings.Join(typeNames, " or "), describeValue(value))
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.validateString(s, value, path)
+	case json.Number:
+		if minimum, ok := s.Minimum.Unpack(); ok {
+			number, _ := new(big.Rat).SetString(value.String())
+			if number.Cmp(new(big.Rat).SetInt64(minimum)) < 0 {
+				v.addf(path, "expected a value of at least %d, but got %s", minimum, value)
+			}
+		}
+	case map[string]any:
+		v.validateObject(s, value, path)
+	case []any:
+		if s.Items != nil {
+			for idx, item := range value {
+				v.validate(s.Items, item, fmt.Sprintf("%s/%d", path, idx))
+			}
+		}
+	}
+}
+
+func (v *validator) validateString(s *Schema, value, path string) {
+	if minLength, ok := s.MinLength.Unpack(); ok && utf8.RuneCountInString(value) < minLength {
+		v.addf(path, "expected a string with at least %d characters, but got %q", minLength, value)
+	}
+	if s.Pattern != "" {
+		rx, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			v.addf(path, "cannot compile pattern %q: %s", s.Pattern, err.Error())
+		} else if !rx.MatchString(value) {
+			v.addf(path, "expected a string matching /%s/, but got %q", s.Pattern, value)
+		}
+	}
+	if s.Format == "date-time" {
+		_, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			v.addf(path, "expected an RFC 3339 timestamp, but got %q", value)
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, value map[string]any, path string) {
+	for _, key := range s.Required {
+		if _, exists := value[key]; !exists {
+			v.addf(path, "missing required property %q", key)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(value)) {
+		subpath := path + "/" + escapeJSONPointer(key)
+		if s.PropertyNames != nil {
+			v.validate(s.PropertyNames, key, subpath)
+		}
+		if sub, exists := s.Properties[key]; exists {
+			v.validate(sub, value[key], subpath)
+		} else if s.AdditionalProperties != nil {
+			v.validate(s.AdditionalProperties, value[key], subpath)
+		}
+	}
+}
+
+// isValid checks `value` against `s` without reporting errors.
+func (v *validator) isValid(s *Schema, value any) bool {
+	sub := validator{root: v.root}
+	sub.validate(s, value, "")
+	return sub.errs.IsEmpty()
+}
+
+// resolve follows references until a schema without a reference is found.
+func (v *validator) resolve(s *Schema) *Schema {
+	for s.Ref != "" {
+		target := v.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if target == nil {
+			return s
+		}
+		s = target
+	}
+	return s
+}
+
+func matchesType(value any, typeName string) bool {
+	switch value := value.(type) {
+	case nil:
+		return typeName == "null" || typeName == ""
+	case bool:
+		return typeName == "boolean" || typeName == ""
+	case string:
+		return typeName == "string" || typeName == ""
+	case json.Number:
+		if typeName == "integer" {
+			number, ok := new(big.Rat).SetString(value.String())
+			return ok && number.IsInt()
+		}
+		return typeName == "number" || typeName == ""
+	case map[string]any:
+		return typeName == "object" || typeName == ""
+	case []any:
+		return typeName == "array" || typeName == ""
+	default:
+		return false
+	}
+}
+
+func describeValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	default:
+		buf, _ := json.Marshal(value) //nolint:errcheck // cannot fail for values decoded from JSON
+		return string(buf)
+	}
+}
+
+func formatJSONList(values []any) string {
+	parts := make([]string, len(values))
+	for idx, value := range values {
+		parts[idx] = describeValue(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// jsonEqual compares a value from a schema with a value decoded from a document.
+func jsonEqual(lhs, rhs any) bool {
+	lhsBuf, err1 := json.Marshal(lhs)
+	rhsBuf, err2 := json.Marshal(rhs)
+	return err1 == nil && err2 == nil && bytes.Equal(lhsBuf, rhsBuf)
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapeJSONPointer(key string) string {
+	return jsonPointerEscaper.Replace(key)
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquidschema
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// docComments holds the doc comments from the source code of package liquid.
+type docComments struct {
+	// The package documentation.
+	Package string
+	// The doc comments of all exported types and their fields, with keys like "TypeName" or "TypeName.FieldName".
+	Decls map[string]string
+}
+
+// parseDocComments collects the doc comments from all source files in the given directory.
+func parseDocComments(sourceDir string) (docComments, error) {
+	paths, err := filepath.Glob(filepath.Join(sourceDir, "*.go"))
+	if err != nil {
+		return docComments{}, err
+	}
+
+	result := docComments{Decls: make(map[string]string)}
+	addDoc := func(key string, groups ...*ast.CommentGroup) {
+		for _, group := range groups {
+			if group == nil {
+				continue
+			}
+			text := strings.TrimSpace(group.Text())
+			if text != "" {
+				result.Decls[key] = text
+				return
+			}
+		}
+	}
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return docComments{}, err
+		}
+		if file.Doc != nil {
+			result.Package = file.Doc.Text()
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec) //nolint:errcheck // type declarations only contain TypeSpecs
+				if !typeSpec.Name.IsExported() {
+					continue
+				}
+				if len(genDecl.Specs) == 1 {
+					addDoc(typeSpec.Name.Name, typeSpec.Doc, genDecl.Doc)
+				} else {
+					addDoc(typeSpec.Name.Name, typeSpec.Doc)
+				}
+
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range structType.Fields.List {
+					for _, name := range field.Names {
+						addDoc(typeSpec.Name.Name+"."+name.Name, field.Doc, field.Comment)
+					}
+				}
+			}
+		}
+	}
+	return result, nil
+}
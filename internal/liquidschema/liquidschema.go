@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package liquidschema generates the JSON schemas for the payload types of the LIQUID API, as well as an OpenAPI document for the API as a whole.
+// The generated files are embedded in package liquid, see [liquid.JSONSchemas] and [liquid.OpenAPIDocument].
+package liquidschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/go-api-declarations/internal/jsonschema"
+	"github.com/sapcc/go-api-declarations/internal/units"
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+// PayloadTypes lists all types that appear as request or response bodies in the LIQUID API.
+var PayloadTypes = []reflect.Type{
+	reflect.TypeFor[liquid.ServiceInfo](),
+	reflect.TypeFor[liquid.ServiceCapacityRequest](),
+	reflect.TypeFor[liquid.ServiceCapacityReport](),
+	reflect.TypeFor[liquid.ServiceUsageRequest](),
+	reflect.TypeFor[liquid.ServiceUsageReport](),
+	reflect.TypeFor[liquid.ServiceQuotaRequest](),
+	reflect.TypeFor[liquid.CommitmentChangeRequest](),
+	reflect.TypeFor[liquid.CommitmentChangeResponse](),
+}
+
+// FileNameFor returns the name of the file containing the schema for the given type.
+func FileNameFor(t reflect.Type) string {
+	return t.Name() + ".schema.json"
+}
+
+// OpenAPIFileName is the name of the file containing the OpenAPI document for the LIQUID API.
+const OpenAPIFileName = "openapi.json"
+
+// Generate renders the schemas for all PayloadTypes, as well as the OpenAPI document.
+// The result maps file names (see FileNameFor and OpenAPIFileName) to file contents.
+//
+// The `sourceDir` must contain the source code of package liquid.
+// Descriptions for types and fields are taken from the doc comments found therein.
+// Endpoints are taken from the "Endpoint:" sections in the package documentation.
+func Generate(sourceDir string) (map[string][]byte, error) {
+	docs, err := parseDocComments(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	g := newGenerator(docs)
+
+	result := make(map[string][]byte, len(PayloadTypes)+1)
+	for _, t := range PayloadTypes {
+		schema, err := g.Generate(t)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate schema for %s: %w", t.Name(), err)
+		}
+		result[FileNameFor(t)], err = encodeJSON(schema)
+		if err != nil {
+			return nil, fmt.Errorf("cannot serialize schema for %s: %w", t.Name(), err)
+		}
+	}
+
+	doc, err := buildOpenAPIDocument(g, docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate OpenAPI document: %w", err)
+	}
+	result[OpenAPIFileName], err = encodeJSON(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize OpenAPI document: %w", err)
+	}
+	return result, nil
+}
+
+func newGenerator(docs docComments) jsonschema.Generator {
+	return jsonschema.Generator{
+		CustomSchemas: customSchemas(),
+		EnumValues: map[reflect.Type][]any{
+			reflect.TypeFor[liquid.Topology](): {
+				liquid.FlatTopology, liquid.AZAwareTopology, liquid.AZSeparatedTopology,
+			},
+			reflect.TypeFor[liquid.MetricType](): {
+				liquid.MetricTypeUnknown, liquid.MetricTypeGauge, liquid.MetricTypeCounter, liquid.MetricTypeStateset,
+				liquid.MetricTypeInfo, liquid.MetricTypeHistogram, liquid.MetricTypeGaugeHistogram, liquid.MetricTypeSummary,
+			},
+			reflect.TypeFor[liquid.CommitmentStatus](): {
+				liquid.CommitmentStatusPlanned, liquid.CommitmentStatusPending, liquid.CommitmentStatusGuaranteed,
+				liquid.CommitmentStatusConfirmed, liquid.CommitmentStatusSuperseded, liquid.CommitmentStatusExpired,
+			},
+		},
+		Describe: func(t reflect.Type, fieldName string) string {
+			if fieldName == "" {
+				return docs.Decls[t.Name()]
+			}
+			return docs.Decls[t.Name()+"."+fieldName]
+		},
+	}
+}
+
+func encodeJSON(data any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(data)
+	return buf.Bytes(), err
+}
+
+func customSchemas() map[reflect.Type]*jsonschema.Schema {
+	symbols := strings.Join(units.UnitSymbols(), "|")
+	identifierSchema := &jsonschema.Schema{Type: "string", Pattern: `^[a-zA-Z][a-zA-Z0-9._-]*$`}
+
+	return map[reflect.Type]*jsonschema.Schema{
+		reflect.TypeFor[liquid.Unit](): {
+			Type:    "string",
+			Pattern: fmt.Sprintf(`^(|%[1]s|[0-9]+ (%[1]s))$`, symbols),
+		},
+		reflect.TypeFor[liquid.AvailabilityZone](): {
+			Type:      "string",
+			MinLength: Some(1),
+			Not:       &jsonschema.Schema{Enum: []any{liquid.AvailabilityZoneTotal}},
+		},
+		reflect.TypeFor[liquid.ResourceName](): identifierSchema,
+		reflect.TypeFor[liquid.RateName]():     identifierSchema,
+		reflect.TypeFor[liquid.CategoryName](): {Type: "string", MinLength: Some(1)},
+	}
+}
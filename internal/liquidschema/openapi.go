@@ -0,0 +1,317 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquidschema
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sapcc/go-api-declarations/internal/errorset"
+	"github.com/sapcc/go-api-declarations/internal/jsonschema"
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification that the generated document conforms to.
+const OpenAPIVersion = "3.1.0"
+
+// These types represent the subset of the OpenAPI specification that we need.
+type (
+	openAPIDocument struct {
+		OpenAPI    string                                  `json:"openapi"`
+		Info       openAPIInfo                             `json:"info"`
+		Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+		Components openAPIComponents                       `json:"components"`
+		Security   []map[string][]string                   `json:"security"`
+	}
+	openAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	}
+	openAPIComponents struct {
+		Schemas         map[string]*jsonschema.Schema    `json:"schemas"`
+		SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+	}
+	openAPISecurityScheme struct {
+		Type        string `json:"type"`
+		In          string `json:"in"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	openAPIOperation struct {
+		OperationID string                     `json:"operationId"`
+		Summary     string                     `json:"summary"`
+		Description string                     `json:"description"`
+		Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+		Responses   map[string]openAPIResponse `json:"responses"`
+	}
+	openAPIParameter struct {
+		Name        string             `json:"name"`
+		In          string             `json:"in"`
+		Description string             `json:"description"`
+		Required    bool               `json:"required"`
+		Schema      *jsonschema.Schema `json:"schema"`
+	}
+	openAPIRequestBody struct {
+		Required bool                        `json:"required"`
+		Content  map[string]openAPIMediaType `json:"content"`
+	}
+	openAPIResponse struct {
+		Description string                      `json:"description"`
+		Content     map[string]openAPIMediaType `json:"content,omitempty"`
+	}
+	openAPIMediaType struct {
+		Schema *jsonschema.Schema `json:"schema"`
+	}
+)
+
+const (
+	openAPIRefPrefix          = "#/components/schemas/"
+	openAPISecuritySchemeName = "keystoneToken"
+)
+
+// endpointDoc is the parsed form of an "Endpoint:" section in the package documentation of package liquid.
+type endpointDoc struct {
+	Method       string
+	Path         string // e.g. "/v1/projects/:uuid/quota"
+	Description  string
+	RequestType  string // or "" if the endpoint does not take a request body
+	ResponseType string // or "" if the endpoint responds with 204
+	NoContent    bool   // whether the endpoint responds with 204
+	ParamDocs    map[string]string
+}
+
+var (
+	endpointHeadingRx    = regexp.MustCompile(`^# Endpoint: ([A-Z]+) (/\S*)$`)
+	requestTypeRx        = regexp.MustCompile(`^\s*- The request body payload must be of type \[(\w+)\]\.$`)
+	responseTypeRx       = regexp.MustCompile(`^\s*- On success, the response body payload must be of type \[(\w+)\]\.$`)
+	noContentRx          = regexp.MustCompile(`^\s*- On success, the response body shall be empty and status 204 \(No Content\) shall be returned\.$`)
+	paramDocRx           = regexp.MustCompile(`^\s*- The "(:\w+)" parameter in the request path (.*)$`)
+	pathParamRx          = regexp.MustCompile(`/:(\w+)`)
+	linkDefinitionRx     = regexp.MustCompile(`^\[[^\]]+\]: `)
+	payloadTypeDeclDocRx = regexp.MustCompile(`^(\w+) is the (request|response) payload format for ([A-Z]+) (/\S*)\.`)
+)
+
+// parseEndpointDocs finds all "Endpoint:" sections in the given package documentation.
+func parseEndpointDocs(packageDoc string) ([]endpointDoc, error) {
+	var (
+		result  []endpointDoc
+		current *endpointDoc
+		errs    errorset.ErrorSet
+	)
+	for line := range strings.Lines(packageDoc) {
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, "# ") {
+			current = nil
+			match := endpointHeadingRx.FindStringSubmatch(line)
+			if match == nil {
+				if strings.HasPrefix(line, "# Endpoint:") {
+					errs.Addf("malformed endpoint heading: %q", line)
+				}
+				continue
+			}
+			result = append(result, endpointDoc{Method: match[1], Path: match[2], ParamDocs: make(map[string]string)})
+			current = &result[len(result)-1]
+			continue
+		}
+		if linkDefinitionRx.MatchString(line) {
+			current = nil // link definitions at the end of the package doc are not part of the last section
+		}
+		if current == nil {
+			continue
+		}
+
+		if match := requestTypeRx.FindStringSubmatch(line); match != nil {
+			current.RequestType = match[1]
+		}
+		if match := responseTypeRx.FindStringSubmatch(line); match != nil {
+			current.ResponseType = match[1]
+		}
+		if match := paramDocRx.FindStringSubmatch(line); match != nil {
+			current.ParamDocs[strings.TrimPrefix(match[1], ":")] = strings.ToUpper(match[2][:1]) + match[2][1:]
+		}
+		if line != "" && !strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			current.Description = strings.TrimSpace(current.Description + "\n" + line)
+		}
+		if noContentRx.MatchString(line) {
+			current.NoContent = true
+		}
+	}
+
+	for _, e := range result {
+		if e.ResponseType == "" && !e.NoContent {
+			errs.Addf("section for endpoint %s %s does not document a response", e.Method, e.Path)
+		}
+		for _, match := range pathParamRx.FindAllStringSubmatch(e.Path, -1) {
+			if e.ParamDocs[match[1]] == "" {
+				errs.Addf("section for endpoint %s %s does not document the %q parameter", e.Method, e.Path, ":"+match[1])
+			}
+		}
+	}
+	if !errs.IsEmpty() {
+		return nil, errors.New(errs.Join(", "))
+	}
+	return result, nil
+}
+
+// buildOpenAPIDocument renders the OpenAPI document describing the LIQUID API.
+func buildOpenAPIDocument(g jsonschema.Generator, docs docComments) (*openAPIDocument, error) {
+	endpoints, err := parseEndpointDocs(docs.Package)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPayloadTypeCoverage(endpoints, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	g.RefPrefix = openAPIRefPrefix
+	schemas, err := g.GenerateDefs(append(slices.Clone(PayloadTypes), reflect.TypeFor[liquid.ProjectUUID]())...)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &openAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: openAPIInfo{
+			Title:       "LIQUID",
+			Description: "The Limes Interface for Quota and Usage Interrogation and Discovery. Please refer to <https://pkg.go.dev/github.com/sapcc/go-api-declarations/liquid> for the full specification.",
+			Version:     "1",
+		},
+		Paths: make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: schemas,
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				openAPISecuritySchemeName: {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        "X-Auth-Token",
+					Description: "A Keystone token. Requests without a valid token are rejected with status 401. Requests with a token that confers insufficient access are rejected with status 403.",
+				},
+			},
+		},
+		Security: []map[string][]string{{openAPISecuritySchemeName: {}}},
+	}
+
+	for _, e := range endpoints {
+		path := pathParamRx.ReplaceAllString(e.Path, "/{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(e.Method)] = buildOpenAPIOperation(e)
+	}
+	return doc, nil
+}
+
+func buildOpenAPIOperation(e endpointDoc) *openAPIOperation {
+	summary, _, _ := strings.Cut(e.Description, "\n")
+	op := &openAPIOperation{
+		OperationID: operationIDFor(e.Method, e.Path),
+		Summary:     summary,
+		Description: e.Description,
+		Responses: map[string]openAPIResponse{
+			"default": {
+				Description: "An error occurred. The response body contains a human-readable error message.",
+				Content: map[string]openAPIMediaType{
+					"text/plain": {Schema: &jsonschema.Schema{Type: "string"}},
+				},
+			},
+		},
+	}
+
+	for _, match := range pathParamRx.FindAllStringSubmatch(e.Path, -1) {
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name:        match[1],
+			In:          "path",
+			Description: e.ParamDocs[match[1]],
+			Required:    true,
+			Schema:      &jsonschema.Schema{Ref: openAPIRefPrefix + "ProjectUUID"},
+		})
+	}
+	if e.RequestType != "" {
+		op.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  jsonContent(e.RequestType),
+		}
+	}
+	if e.NoContent {
+		op.Responses["204"] = openAPIResponse{Description: http.StatusText(http.StatusNoContent)}
+	} else {
+		op.Responses["200"] = openAPIResponse{Description: http.StatusText(http.StatusOK), Content: jsonContent(e.ResponseType)}
+	}
+	return op
+}
+
+func jsonContent(typeName string) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{
+		"application/json": {Schema: &jsonschema.Schema{Ref: openAPIRefPrefix + typeName}},
+	}
+}
+
+// operationIDFor builds an operation ID like "postReportCapacity" from the method and the static parts of the path.
+func operationIDFor(method, path string) string {
+	result := strings.ToLower(method)
+	segments := strings.Split(strings.TrimPrefix(path, "/v1/"), "/")
+	segments = slices.DeleteFunc(segments, func(s string) bool { return strings.HasPrefix(s, ":") })
+	lastSegment := segments[len(segments)-1]
+	for word := range strings.SplitSeq(lastSegment, "-") {
+		result += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return result
+}
+
+// checkPayloadTypeCoverage checks that the endpoint docs and the PayloadTypes list agree with each other,
+// as well as with the doc comments on payload types of the form "X is the request payload format for METHOD PATH".
+func checkPayloadTypeCoverage(endpoints []endpointDoc, docs docComments) error {
+	var errs errorset.ErrorSet
+
+	isPayloadType := make(map[string]bool)
+	for _, t := range PayloadTypes {
+		isPayloadType[t.Name()] = true
+	}
+	usedTypes := make(map[string]bool)
+	for _, e := range endpoints {
+		for _, typeName := range []string{e.RequestType, e.ResponseType} {
+			if typeName == "" {
+				continue
+			}
+			usedTypes[typeName] = true
+			if !isPayloadType[typeName] {
+				errs.Addf("type %s is used by endpoint %s %s, but is missing in liquidschema.PayloadTypes", typeName, e.Method, e.Path)
+			}
+		}
+	}
+	for _, t := range PayloadTypes {
+		if !usedTypes[t.Name()] {
+			errs.Addf("type %s is listed in liquidschema.PayloadTypes, but is not used by any endpoint", t.Name())
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(docs.Decls)) {
+		match := payloadTypeDeclDocRx.FindStringSubmatch(docs.Decls[key])
+		if match == nil {
+			continue
+		}
+		typeName, direction, method, path := match[1], match[2], match[3], match[4]
+		idx := slices.IndexFunc(endpoints, func(e endpointDoc) bool { return e.Method == method && e.Path == path })
+		switch {
+		case idx == -1:
+			errs.Addf("type %s is documented as %s payload format for %s %s, but there is no such endpoint", typeName, direction, method, path)
+		case direction == "request" && endpoints[idx].RequestType != typeName,
+			direction == "response" && endpoints[idx].ResponseType != typeName:
+			errs.Addf("type %s is documented as %s payload format for %s %s, but the endpoint documentation does not agree", typeName, direction, method, path)
+		}
+	}
+
+	if !errs.IsEmpty() {
+		return errors.New(errs.Join(", "))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquidschema
+
+import (
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+)
+
+func TestParseEndpointDocs(t *testing.T) {
+	endpoints, err := parseEndpointDocs(`Package foo does things.
+
+# Endpoint: GET /v1/things/:id
+
+Shows a thing.
+It might be a big thing.
+  - The ":id" parameter in the request path must identify a thing.
+  - On success, the response body payload must be of type [Thing].
+
+# Endpoint: DELETE /v1/things/:id
+
+Deletes a thing.
+  - The ":id" parameter in the request path must identify a thing.
+  - On success, the response body shall be empty and status 204 (No Content) shall be returned.
+
+[Thing]: https://example.com/
+`)
+	assert.ErrEqual(t, err, nil)
+	assert.DeepEqual(t, "endpoints", endpoints, []endpointDoc{
+		{
+			Method:       "GET",
+			Path:         "/v1/things/:id",
+			Description:  "Shows a thing.\nIt might be a big thing.",
+			ResponseType: "Thing",
+			ParamDocs:    map[string]string{"id": "Must identify a thing."},
+		},
+		{
+			Method:      "DELETE",
+			Path:        "/v1/things/:id",
+			Description: "Deletes a thing.",
+			NoContent:   true,
+			ParamDocs:   map[string]string{"id": "Must identify a thing."},
+		},
+	})
+	assert.Equal(t, operationIDFor("GET", "/v1/things/:id"), "getThings")
+	assert.Equal(t, operationIDFor("POST", "/v1/report-capacity"), "postReportCapacity")
+
+	_, err = parseEndpointDocs(`# Endpoint: GET /v1/things/:id
+
+Shows a thing.
+
+# Endpoint: frobnicate the things
+`)
+	assert.ErrEqual(t, err, `malformed endpoint heading: "# Endpoint: frobnicate the things", `+
+		`section for endpoint GET /v1/things/:id does not document a response, `+
+		`section for endpoint GET /v1/things/:id does not document the ":id" parameter`)
+}
+
+func TestCheckPayloadTypeCoverage(t *testing.T) {
+	endpoints := []endpointDoc{
+		{Method: "GET", Path: "/v1/info", ResponseType: "ServiceInfo"},
+		{Method: "POST", Path: "/v1/frobnicate", RequestType: "FrobnicationRequest", NoContent: true},
+	}
+	docs := docComments{Decls: map[string]string{
+		"ServiceInfo":          "ServiceInfo is the response payload format for GET /v1/info.",
+		"FrobnicationRequest":  "FrobnicationRequest is the request payload format for POST /v1/frobnicate.",
+		"FrobnicationResponse": "FrobnicationResponse is the response payload format for POST /v1/frobnicate.",
+		"ServiceQuotaRequest":  "ServiceQuotaRequest is the request payload format for PUT /v1/projects/:uuid/quota.",
+	}}
+
+	err := checkPayloadTypeCoverage(endpoints, docs)
+	assert.ErrEqual(t, err, "type FrobnicationRequest is used by endpoint POST /v1/frobnicate, but is missing in liquidschema.PayloadTypes, "+
+		"type ServiceCapacityRequest is listed in liquidschema.PayloadTypes, but is not used by any endpoint, "+
+		"type ServiceCapacityReport is listed in liquidschema.PayloadTypes, but is not used by any endpoint, "+
+		"type ServiceUsageRequest is listed in liquidschema.PayloadTypes, but is not used by any endpoint, "+
+		"type ServiceUsageReport is listed in liquidschema.PayloadTypes, but is not used by any endpoint, "+
+		"type ServiceQuotaRequest is listed in liquidschema.PayloadTypes, but is not used by any endpoint, "+
+		"type CommitmentChangeRequest is listed in liquidschema.PayloadTypes, but is not used by any endpoint, "+
+		"type CommitmentChangeResponse is listed in liquidschema.PayloadTypes, but is not used by any endpoint, "+
+		"type FrobnicationResponse is documented as response payload format for POST /v1/frobnicate, but the endpoint documentation does not agree, "+
+		"type ServiceQuotaRequest is documented as request payload format for PUT /v1/projects/:uuid/quota, but there is no such endpoint")
+}
@@ -33,6 +33,10 @@ const (
 	BaseUnitPiece BaseUnit = "piece"
 	// BaseUnitBytes is used for resources that are measured in bytes or any multiple thereof.
 	BaseUnitBytes BaseUnit = "B"
+	// BaseUnitBits is used for resources or rates that are measured in bits or any multiple thereof.
+	BaseUnitBits BaseUnit = "bit"
+	// BaseUnitSeconds is used for resources or rates that are measured in seconds or any multiple thereof (e.g. CPU time).
+	BaseUnitSeconds BaseUnit = "s"
 )
 
 // Format is a bitfield enumerating permissible formats for describing amounts.
@@ -50,19 +54,85 @@ const (
 	NumberWithUnitFormat
 )
 
+// prefixFamily is used to group units with prefixes, e.g. KiB and MiB (binary) vs. kB and MB (decimal).
+type prefixFamily int
+
+const (
+	// used by base units (e.g. "B") and units without prefixes (e.g. "min"); these belong to every family
+	//
+	// When used to choose a family for formatting, this denotes the default family for the respective base unit (see defaultPrefixFamily()).
+	noPrefixFamily prefixFamily = iota
+	binaryPrefixFamily
+	decimalPrefixFamily
+)
+
+// defaultPrefixFamily returns the prefix family that is used for formatting
+// amounts of the given base unit, unless another family is chosen explicitly.
+// This is the first prefix family listed for this base unit in bareUnitDefs,
+// e.g. binary prefixes for bytes. Since the decimal prefixes for bytes were
+// added later, this ensures that units which could be represented before
+// are still formatted in the same way.
+func defaultPrefixFamily(base BaseUnit) prefixFamily {
+	for _, def := range bareUnitDefs {
+		if def.Amount.Base == base && def.Family != noPrefixFamily {
+			return def.Family
+		}
+	}
+	return noPrefixFamily
+}
+
+// normalizePrefixFamily returns noPrefixFamily if the given family is the default for this base unit.
+// This ensures that units can be compared with == regardless of whether their prefix family was chosen explicitly.
+func normalizePrefixFamily(base BaseUnit, family prefixFamily) prefixFamily {
+	if family == defaultPrefixFamily(base) {
+		return noPrefixFamily
+	}
+	return family
+}
+
 var bareUnitDefs = []struct {
 	Symbol string
 	Amount Amount
+	Family prefixFamily
 }{
-	{"piece", Amount{BaseUnitPiece, 1}},
-	// the algorithm in Amount.Format() relies on entries in this list for the same base unit being sorted in descending order of amount
-	{"EiB", Amount{BaseUnitBytes, 1 << 60}},
-	{"PiB", Amount{BaseUnitBytes, 1 << 50}},
-	{"TiB", Amount{BaseUnitBytes, 1 << 40}},
-	{"GiB", Amount{BaseUnitBytes, 1 << 30}},
-	{"MiB", Amount{BaseUnitBytes, 1 << 20}},
-	{"KiB", Amount{BaseUnitBytes, 1 << 10}},
-	{"B", Amount{BaseUnitBytes, 1}},
+	{"piece", Amount{BaseUnitPiece, 1}, noPrefixFamily},
+	// binary prefixes for bytes
+	{"EiB", Amount{BaseUnitBytes, 1 << 60}, binaryPrefixFamily},
+	{"PiB", Amount{BaseUnitBytes, 1 << 50}, binaryPrefixFamily},
+	{"TiB", Amount{BaseUnitBytes, 1 << 40}, binaryPrefixFamily},
+	{"GiB", Amount{BaseUnitBytes, 1 << 30}, binaryPrefixFamily},
+	{"MiB", Amount{BaseUnitBytes, 1 << 20}, binaryPrefixFamily},
+	{"KiB", Amount{BaseUnitBytes, 1 << 10}, binaryPrefixFamily},
+	// decimal (SI) prefixes for bytes
+	{"EB", Amount{BaseUnitBytes, 1e18}, decimalPrefixFamily},
+	{"PB", Amount{BaseUnitBytes, 1e15}, decimalPrefixFamily},
+	{"TB", Amount{BaseUnitBytes, 1e12}, decimalPrefixFamily},
+	{"GB", Amount{BaseUnitBytes, 1e9}, decimalPrefixFamily},
+	{"MB", Amount{BaseUnitBytes, 1e6}, decimalPrefixFamily},
+	{"kB", Amount{BaseUnitBytes, 1e3}, decimalPrefixFamily},
+	{"B", Amount{BaseUnitBytes, 1}, noPrefixFamily},
+	// decimal (SI) prefixes for bits
+	{"Ebit", Amount{BaseUnitBits, 1e18}, decimalPrefixFamily},
+	{"Pbit", Amount{BaseUnitBits, 1e15}, decimalPrefixFamily},
+	{"Tbit", Amount{BaseUnitBits, 1e12}, decimalPrefixFamily},
+	{"Gbit", Amount{BaseUnitBits, 1e9}, decimalPrefixFamily},
+	{"Mbit", Amount{BaseUnitBits, 1e6}, decimalPrefixFamily},
+	{"kbit", Amount{BaseUnitBits, 1e3}, decimalPrefixFamily},
+	{"bit", Amount{BaseUnitBits, 1}, noPrefixFamily},
+	// multiples of seconds
+	{"h", Amount{BaseUnitSeconds, 3600}, noPrefixFamily},
+	{"min", Amount{BaseUnitSeconds, 60}, noPrefixFamily},
+	{"s", Amount{BaseUnitSeconds, 1}, noPrefixFamily},
+}
+
+// UnitSymbols returns the symbols of all units that can appear in the serialization of an Amount, e.g. "piece" or "KiB".
+// Within each base unit, larger units come before smaller units.
+func UnitSymbols() []string {
+	result := make([]string, len(bareUnitDefs))
+	for idx, def := range bareUnitDefs {
+		result[idx] = def.Symbol
+	}
+	return result
 }
 
 // ParseAmount parses a string representation of an amount, in one of the following forms:
@@ -70,6 +140,13 @@ var bareUnitDefs = []struct {
 //   - "<amount> <unit>", e.g. "23 MiB"
 //   - "<unit>", e.g. "KiB" (only with allowBareUnit = true)
 func ParseAmount(input string, formats Format) (Amount, error) {
+	amount, _, err := parseAmountWithFamily(input, formats)
+	return amount, err
+}
+
+// parseAmountWithFamily is like ParseAmount, but additionally returns the
+// prefix family of the unit symbol that appeared in the input.
+func parseAmountWithFamily(input string, formats Format) (Amount, prefixFamily, error) {
 	acceptEmpty := (formats & EmptyFormat) == EmptyFormat
 	acceptNumberOnly := (formats & NumberOnlyFormat) == NumberOnlyFormat
 	acceptUnitOnly := (formats & UnitOnlyFormat) == UnitOnlyFormat
@@ -79,18 +156,18 @@ func ParseAmount(input string, formats Format) (Amount, error) {
 	switch len(fields) {
 	case 0:
 		if acceptEmpty {
-			return Amount{BaseUnitNone, 1}, nil
+			return Amount{BaseUnitNone, 1}, noPrefixFamily, nil
 		}
 
 	case 1:
 		if acceptUnitOnly {
 			for _, def := range bareUnitDefs {
 				if def.Symbol == fields[0] {
-					return def.Amount, nil
+					return def.Amount, def.Family, nil
 				}
 			}
 			if !acceptNumberOnly {
-				return Amount{}, fmt.Errorf("invalid value %q: not a known unit name", input)
+				return Amount{}, noPrefixFamily, fmt.Errorf("invalid value %q: not a known unit name", input)
 			}
 		}
 
@@ -98,34 +175,35 @@ func ParseAmount(input string, formats Format) (Amount, error) {
 			number, err := strconv.ParseUint(fields[0], 10, 64)
 			if err != nil {
 				if acceptUnitOnly {
-					return Amount{}, fmt.Errorf("invalid value %q: not a known unit name, and parsing as number failed with: %w", input, err)
+					return Amount{}, noPrefixFamily, fmt.Errorf("invalid value %q: not a known unit name, and parsing as number failed with: %w", input, err)
 				} else {
-					return Amount{}, fmt.Errorf("invalid value %q: %w", input, err)
+					return Amount{}, noPrefixFamily, fmt.Errorf("invalid value %q: %w", input, err)
 				}
 			}
-			return Amount{BaseUnitNone, number}, nil
+			return Amount{BaseUnitNone, number}, noPrefixFamily, nil
 		}
 
 	case 2:
 		if acceptNumberWithUnit {
 			number, err := strconv.ParseUint(fields[0], 10, 64)
 			if err != nil {
-				return Amount{}, fmt.Errorf("invalid value %q: %w", input, err)
+				return Amount{}, noPrefixFamily, fmt.Errorf("invalid value %q: %w", input, err)
 			}
 			for _, def := range bareUnitDefs {
 				if def.Symbol == fields[1] {
-					return def.Amount.MultiplyBy(number)
+					amount, err := def.Amount.MultiplyBy(number)
+					return amount, def.Family, err
 				}
 			}
-			return Amount{}, fmt.Errorf("invalid value %q: no such unit", input)
+			return Amount{}, noPrefixFamily, fmt.Errorf("invalid value %q: no such unit", input)
 		}
 	}
 
 	desc, multipleFormats := formats.Description()
 	if multipleFormats {
-		return Amount{}, fmt.Errorf(`value %q does not match any expected format (%s)`, input, desc)
+		return Amount{}, noPrefixFamily, fmt.Errorf(`value %q does not match any expected format (%s)`, input, desc)
 	} else {
-		return Amount{}, fmt.Errorf(`value %q does not match expected format (%s)`, input, desc)
+		return Amount{}, noPrefixFamily, fmt.Errorf(`value %q does not match expected format (%s)`, input, desc)
 	}
 }
 
@@ -146,17 +224,22 @@ func (a Amount) MultiplyBy(factor uint64) (Amount, error) {
 // Format serializes this Amount into a string representation.
 // Out of the given formats, the shortest possible format will be used.
 // Panics if none of the allowed formats can represent this Amount.
+//
+// Units are chosen from the default prefix family of the amount's base unit,
+// e.g. Amount{BaseUnitBytes, 2000000} -> "2000000 B" (not "2 MB").
 func (a Amount) Format(formats Format) string {
+	return a.formatInFamily(formats, noPrefixFamily)
+}
+
+// formatInFamily is like Format, but uses units from the given prefix family
+// (or the default prefix family if noPrefixFamily is given).
+func (a Amount) formatInFamily(formats Format, family prefixFamily) string {
 	// for measured units, find the best unit to display them in without loss of precision
 	// e.g. Amount{BaseUnitBytes, 524288} -> "512 KiB" (not "0.5 MiB" because amounts do not support fractional numbers)
-	//
-	// NOTE: This relies on `bareUnitDefs` being sorted such that the first match is always the best.
 	unitSymbol, number := string(a.Base), a.Factor
-	for _, def := range bareUnitDefs {
-		if def.Amount.Base == a.Base && a.Factor%def.Amount.Factor == 0 {
-			unitSymbol, number = def.Symbol, a.Factor/def.Amount.Factor
-			break
-		}
+	if idx := a.bestUnitDefIndex(family); idx >= 0 {
+		def := bareUnitDefs[idx]
+		unitSymbol, number = def.Symbol, a.Factor/def.Amount.Factor
 	}
 
 	// generate the most compact format that the caller allows
@@ -190,6 +273,28 @@ func (a Amount) Format(formats Format) string {
 	}
 }
 
+// Returns the index into bareUnitDefs of the largest unit from the given prefix family
+// (or the default prefix family if noPrefixFamily is given) that represents
+// this amount exactly, or -1 if no unit matches (i.e. for BaseUnitNone).
+func (a Amount) bestUnitDefIndex(family prefixFamily) int {
+	if family == noPrefixFamily {
+		family = defaultPrefixFamily(a.Base)
+	}
+	result := -1
+	for idx, def := range bareUnitDefs {
+		if def.Amount.Base != a.Base || (def.Family != family && def.Family != noPrefixFamily) {
+			continue
+		}
+		if a.Factor%def.Amount.Factor != 0 {
+			continue
+		}
+		if result == -1 || def.Amount.Factor > bareUnitDefs[result].Amount.Factor {
+			result = idx
+		}
+	}
+	return result
+}
+
 // Description formats this set of formats as a description for use in error messages:
 //
 //	desc, _ := (units.UnitOnlyFormat | unit.NumberWithUnitFormat).Description()
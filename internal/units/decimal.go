@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package units
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FormatRounded is like String, but may round the value to produce a shorter
+// and more readable representation. This is intended for display purposes
+// (e.g. in dashboards), and must not be used where the value needs to be
+// parsed back exactly.
+//
+// The value is displayed in the largest unit from the same family of prefixes
+// as the value's unit (i.e. binary prefixes like KiB/MiB or decimal prefixes
+// like kB/MB) that is not larger than the value itself, with at most
+// `precision` fractional digits. If this requires rounding, the result is
+// prefixed with a tilde:
+//
+//	LimesV1ValueWithUnit{1536, UnitGibibytes}.FormatRounded(2)   // "1.5 TiB"
+//	LimesV1ValueWithUnit{1073741825, UnitBytes}.FormatRounded(2) // "~1 GiB"
+//	LimesV1ValueWithUnit{1600, UnitMegabytes}.FormatRounded(0)   // "~2 GB"
+//	LimesV1ValueWithUnit{42, UnitNone}.FormatRounded(2)          // "42"
+func (v LimesV1ValueWithUnit) FormatRounded(precision int) string {
+	if v.Unit == UnitNone || v.Unit == realUnitNone {
+		return strconv.FormatUint(v.Value, 10)
+	}
+	baseUnit, multiple := v.Unit.Base()
+	base := baseUnit.amount.Base
+	total := new(big.Int).Mul(new(big.Int).SetUint64(v.Value), new(big.Int).SetUint64(multiple))
+
+	// use the same family of prefixes as the unit does
+	family := v.Unit.family
+	if family == noPrefixFamily {
+		family = defaultPrefixFamily(base)
+	}
+
+	// choose the largest unit that does not exceed the value (or the original unit if the value is zero)
+	chosenIdx := -1
+	if total.Sign() == 0 {
+		chosenIdx = v.Unit.amount.bestUnitDefIndex(family)
+	}
+	for idx, def := range bareUnitDefs {
+		if def.Amount.Base != base || (def.Family != family && def.Family != noPrefixFamily) {
+			continue
+		}
+		if new(big.Int).SetUint64(def.Amount.Factor).Cmp(total) > 0 {
+			continue
+		}
+		if chosenIdx == -1 || def.Amount.Factor > bareUnitDefs[chosenIdx].Amount.Factor {
+			chosenIdx = idx
+		}
+	}
+	if chosenIdx == -1 {
+		// defense in depth: not reachable in practice because every base unit other than BaseUnitNone has a unit with factor 1
+		return v.String()
+	}
+
+	def := bareUnitDefs[chosenIdx]
+	number := new(big.Rat).SetFrac(total, new(big.Int).SetUint64(def.Amount.Factor))
+	return formatRat(number, precision) + " " + def.Symbol
+}
+
+// FormatRounded is like String, but may round the unit's multiplier to
+// produce a shorter and more readable representation. Unlike String, the
+// multiplier is always included, e.g. "1 GiB" instead of "GiB". See
+// documentation on LimesV1ValueWithUnit.FormatRounded() for details.
+func (u Unit) FormatRounded(precision int) string {
+	if u == UnitNone {
+		return ""
+	}
+	return LimesV1ValueWithUnit{Value: 1, Unit: u}.FormatRounded(precision)
+}
+
+// FormatRounded is like String, but may round the value to produce a shorter
+// and more readable representation. See documentation on
+// LimesV1ValueWithUnit.FormatRounded() for details.
+func (q Quantity) FormatRounded(precision int) string {
+	return LimesV1ValueWithUnit(q).FormatRounded(precision)
+}
+
+// formatRat formats a rational number with at most the given number of
+// fractional digits. Trailing zeros are removed. If the result is not exact,
+// it is prefixed with a tilde.
+func formatRat(number *big.Rat, precision int) string {
+	precision = max(precision, 0)
+	result := number.FloatString(precision)
+	if strings.Contains(result, ".") {
+		result = strings.TrimRight(strings.TrimRight(result, "0"), ".")
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	if !new(big.Rat).Mul(number, new(big.Rat).SetInt(scale)).IsInt() {
+		result = "~" + result
+	}
+	return result
+}
+
+var decimalNumberRx = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// parseDecimalInUnit implements ParseInUnit for inputs with a fractional number, e.g. "1.5 TiB".
+// The fields are the result of strings.Fields(input).
+func parseDecimalInUnit(u Unit, input string, fields []string) (uint64, error) {
+	number, ok := new(big.Rat).SetString(fields[0])
+	if !ok {
+		// defense in depth: not reachable in practice because the caller checks against decimalNumberRx
+		return 0, fmt.Errorf("invalid value %q: not a decimal number", input)
+	}
+
+	sourceUnit := realUnitNone
+	switch len(fields) {
+	case 1:
+		// a bare number, which is only acceptable for UnitNone (but that is checked below)
+	case 2:
+		amount, family, err := parseAmountWithFamily(fields[1], UnitOnlyFormat)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q: no such unit", input)
+		}
+		sourceUnit = Unit{amount, normalizePrefixFamily(amount.Base, family)}
+	default:
+		desc, _ := (NumberOnlyFormat | NumberWithUnitFormat).Description()
+		return 0, fmt.Errorf(`value %q does not match any expected format (%s)`, input, desc)
+	}
+
+	sourceBase, sourceMultiple := sourceUnit.Base()
+	targetBase, targetMultiple := u.Base()
+	if sourceBase != targetBase {
+		return 0, fmt.Errorf(
+			"cannot convert value %q to %s because units are incompatible",
+			strings.Join(fields, " "), toStringForError(u),
+		)
+	}
+
+	result := new(big.Rat).Mul(number, new(big.Rat).SetFrac(
+		new(big.Int).SetUint64(sourceMultiple),
+		new(big.Int).SetUint64(targetMultiple),
+	))
+	if !result.IsInt() {
+		unitStr := toStringForError(u)
+		if strings.Contains(unitStr, " ") { // unit has a numeric multiplier by itself, e.g. "4 MiB"
+			unitStr = "x " + unitStr
+		}
+		return 0, fmt.Errorf(
+			"value %q cannot be represented as integer number of %s (would be %s %s)",
+			strings.Join(fields, " "), toStringForError(u), formatRat(result, 3), unitStr,
+		)
+	}
+	if !result.Num().IsUint64() {
+		return 0, fmt.Errorf(
+			"value %q cannot be represented in %s without overflow",
+			strings.Join(fields, " "), toStringForError(u),
+		)
+	}
+	return result.Num().Uint64(), nil
+}
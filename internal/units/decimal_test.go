@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package units
+
+import (
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+)
+
+func TestFormatRounded(t *testing.T) {
+	test := func(value uint64, unit Unit, precision int, expected string) {
+		t.Helper()
+		assert.Equal(t, LimesV1ValueWithUnit{Value: value, Unit: unit}.FormatRounded(precision), expected)
+		assert.Equal(t, Quantity{Value: value, Unit: unit}.FormatRounded(precision), expected)
+	}
+
+	// examples from the docstring
+	test(1536, UnitGibibytes, 2, "1.5 TiB")
+	test(1073741825, UnitBytes, 2, "~1 GiB")
+	test(1600, UnitMegabytes, 0, "~2 GB")
+	test(42, UnitNone, 2, "42")
+
+	// exact values are never rounded
+	test(1024, UnitMebibytes, 2, "1 GiB")
+	test(1000, UnitMebibytes, 2, "1000 MiB")
+	test(1000, UnitMegabytes, 2, "1 GB")
+	test(1, UnitBytes, 2, "1 B")
+	test(500, UnitPiece, 2, "500 piece")
+
+	// the precision is respected, and trailing zeros are removed
+	test(1100, UnitMebibytes, 0, "~1 GiB")
+	test(1100, UnitMebibytes, 1, "~1.1 GiB")
+	test(1100, UnitMebibytes, 3, "~1.074 GiB")
+	test(1100, UnitMebibytes, 6, "~1.074219 GiB")
+	test(1100, UnitMebibytes, 10, "1.07421875 GiB")
+	test(1100, UnitMebibytes, -1, "~1 GiB")
+
+	// units without prefixes default to the first prefix family (binary prefixes in the case of bytes)
+	test(1500000, UnitBytes, 2, "~1.43 MiB")
+	test(1500000, UnitBits, 2, "1.5 Mbit")
+	test(5400, UnitSeconds, 2, "1.5 h")
+	test(100, UnitSeconds, 2, "~1.67 min")
+
+	// values in non-standard units, and zero values
+	test(3, mustMultiply(UnitMebibytes, 512), 1, "1.5 GiB")
+	test(0, UnitMebibytes, 2, "0 MiB")
+	test(0, UnitNone, 2, "0")
+
+	// values that would overflow Amount
+	test(1<<20, UnitExbibytes, 2, "1048576 EiB")
+
+	// formatting of units
+	assert.Equal(t, UnitGibibytes.FormatRounded(2), "1 GiB")
+	assert.Equal(t, mustMultiply(UnitMebibytes, 1100).FormatRounded(1), "~1.1 GiB")
+	assert.Equal(t, UnitNone.FormatRounded(2), "")
+}
+
+func TestParseInUnitWithDecimals(t *testing.T) {
+	value, err := ParseInUnit(UnitGibibytes, "1.5 TiB")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 1536)
+
+	value, err = ParseInUnit(UnitMegabytes, "2.5 GB")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 2500)
+
+	value, err = ParseInUnit(UnitGibibytes, "2.0 GiB")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 2)
+
+	value, err = ParseInUnit(UnitNone, "42.0")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 42)
+
+	value, err = ParseInUnit(UnitSeconds, "0.5 min")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 30)
+
+	_, err = ParseInUnit(UnitGibibytes, "1.3 GiB")
+	assert.ErrEqual(t, err, `value "1.3 GiB" cannot be represented as integer number of GiB (would be 1.3 GiB)`)
+
+	_, err = ParseInUnit(UnitBytes, "1.3 KiB")
+	assert.ErrEqual(t, err, `value "1.3 KiB" cannot be represented as integer number of B (would be 1331.2 B)`)
+
+	_, err = ParseInUnit(mustMultiply(UnitMebibytes, 4), "1.5 MiB")
+	assert.ErrEqual(t, err, `value "1.5 MiB" cannot be represented as integer number of 4 MiB (would be 0.375 x 4 MiB)`)
+
+	_, err = ParseInUnit(UnitNone, "42.5")
+	assert.ErrEqual(t, err, `value "42.5" cannot be represented as integer number of <count> (would be 42.5 <count>)`)
+
+	_, err = ParseInUnit(UnitBytes, "1.5 bit")
+	assert.ErrEqual(t, err, `cannot convert value "1.5 bit" to B because units are incompatible`)
+
+	_, err = ParseInUnit(UnitBytes, "1.5 XiB")
+	assert.ErrEqual(t, err, `invalid value "1.5 XiB": no such unit`)
+
+	_, err = ParseInUnit(UnitBytes, "1.5 KiB extra")
+	assert.ErrEqual(t, err, `value "1.5 KiB extra" does not match any expected format ("<number>" or "<number> <unit>")`)
+
+	_, err = ParseInUnit(UnitBytes, "16.5 EiB")
+	assert.ErrEqual(t, err, `value "16.5 EiB" cannot be represented in B without overflow`)
+}
@@ -5,27 +5,38 @@ package units
 
 import (
 	"fmt"
+	"math/bits"
 	"strconv"
 	"strings"
 )
 
 // ParseInUnit parses the string representation of a value with this unit
-// (or any unit that can be converted to it).
+// (or any unit that can be converted to it). Fractional numbers are accepted
+// as long as the result is an integer in the target unit.
 //
-//	ParseInUnit(UnitMebibytes, "10 MiB") -> 10
-//	ParseInUnit(UnitMebibytes, "10 GiB") -> 10240
-//	ParseInUnit(UnitMebibytes, "10 KiB") -> error: incompatible unit
-//	ParseInUnit(UnitMebibytes, "10")     -> error: missing unit
-//	ParseInUnit(UnitNone, "42")          -> 42
-//	ParseInUnit(UnitNone, "42 MiB")      -> error: unexpected unit
+//	ParseInUnit(UnitMebibytes, "10 MiB")  -> 10
+//	ParseInUnit(UnitMebibytes, "10 GiB")  -> 10240
+//	ParseInUnit(UnitMebibytes, "10 KiB")  -> error: incompatible unit
+//	ParseInUnit(UnitMebibytes, "10")      -> error: missing unit
+//	ParseInUnit(UnitKilobytes, "125 KiB") -> 128
+//	ParseInUnit(UnitKibibytes, "1 MB")    -> error: not an integer number of KiB
+//	ParseInUnit(UnitGibibytes, "1.5 TiB") -> 1536
+//	ParseInUnit(UnitGibibytes, "1.3 GiB") -> error: not an integer number of GiB
+//	ParseInUnit(UnitNone, "42")           -> 42
+//	ParseInUnit(UnitNone, "42 MiB")       -> error: unexpected unit
 func ParseInUnit(u Unit, str string) (uint64, error) {
-	amount, err := ParseAmount(str, NumberOnlyFormat|NumberWithUnitFormat)
+	fields := strings.Fields(str)
+	if len(fields) > 0 && decimalNumberRx.MatchString(fields[0]) {
+		return parseDecimalInUnit(u, str, fields)
+	}
+
+	amount, family, err := parseAmountWithFamily(str, NumberOnlyFormat|NumberWithUnitFormat)
 	if err != nil {
 		return 0, err
 	}
 	value := LimesV1ValueWithUnit{
 		Value: amount.Factor,
-		Unit:  Unit{Amount{Base: amount.Base, Factor: 1}},
+		Unit:  Unit{amount: Amount{Base: amount.Base, Factor: 1}, family: normalizePrefixFamily(amount.Base, family)},
 	}
 	converted, err := value.ConvertTo(u)
 	return converted.Value, err
@@ -46,19 +57,22 @@ type LimesV1ValueWithUnit struct {
 //	// prints: 1 TiB
 //	fmt.Println(LimesV1ValueWithUnit{1048576,UnitMebibytes})
 func (v LimesV1ValueWithUnit) String() string {
+	if v.Unit == UnitNone {
+		v.Unit = realUnitNone // otherwise, the multiplication below would always yield 0
+	}
 	amount, err := v.Unit.amount.MultiplyBy(v.Value)
 	if err == nil {
-		return amount.Format(NumberOnlyFormat | NumberWithUnitFormat)
+		return amount.formatInFamily(NumberOnlyFormat|NumberWithUnitFormat, v.Unit.family)
 	}
 
 	// fallback: if converting to the base unit would overflow, print without conversion
 	valueStr := strconv.FormatUint(v.Value, 10)
-	if v.Unit == UnitNone {
+	if v.Unit == realUnitNone {
 		// defense in depth: not reachable in practice because LimesV1ValueWithUnit with
 		// UnitNone would not be able to overflow MultiplyBy() above
 		return valueStr
 	} else {
-		unitStr := v.Unit.amount.Format(UnitOnlyFormat | NumberWithUnitFormat)
+		unitStr := v.Unit.String()
 		if strings.Contains(unitStr, " ") { // unit has a numeric multiplier by itself, e.g. "4 MiB"
 			return valueStr + " x " + unitStr // e.g. "20 x 4 MiB"
 		} else {
@@ -69,7 +83,11 @@ func (v LimesV1ValueWithUnit) String() string {
 
 // ConvertTo returns an equal value in the given Unit. An error is returned if:
 //   - the source unit cannot be converted to the target unit, or
-//   - the conversion does not yield an integer value in the new unit.
+//   - the conversion does not yield an integer value in the new unit, or
+//   - the value in the new unit does not fit into a uint64.
+//
+// Conversions between different prefix families of the same base unit
+// (e.g. from GiB to MB) are supported as long as the result is exact.
 func (v LimesV1ValueWithUnit) ConvertTo(u Unit) (LimesV1ValueWithUnit, error) {
 	if v.Unit == u {
 		return v, nil
@@ -84,16 +102,24 @@ func (v LimesV1ValueWithUnit) ConvertTo(u Unit) (LimesV1ValueWithUnit, error) {
 		)
 	}
 
-	valueInBase := v.Value * sourceMultiple
-	if valueInBase%targetMultiple != 0 {
+	// the value in the base unit may exceed the range of uint64 even if the result does not
+	hi, lo := bits.Mul64(v.Value, sourceMultiple)
+	if hi >= targetMultiple {
 		return LimesV1ValueWithUnit{}, fmt.Errorf(
-			"value %q cannot be represented as integer number of %s",
+			"value %q cannot be represented in %s without overflow",
 			v.String(), toStringForError(u),
 		)
 	}
+	quotient, remainder := bits.Div64(hi, lo, targetMultiple)
+	if remainder != 0 {
+		return LimesV1ValueWithUnit{}, fmt.Errorf(
+			"value %q cannot be represented as integer number of %s (remainder: %s)",
+			v.String(), toStringForError(u), Amount{Base: base.amount.Base, Factor: remainder}.Format(NumberOnlyFormat|NumberWithUnitFormat),
+		)
+	}
 
 	return LimesV1ValueWithUnit{
-		Value: valueInBase / targetMultiple,
+		Value: quotient,
 		Unit:  u,
 	}, nil
 }
@@ -22,7 +22,7 @@ func TestParseInUnit(t *testing.T) {
 	assert.Equal(t, value, 10240)
 
 	_, err = ParseInUnit(UnitMebibytes, "10 KiB")
-	assert.ErrEqual(t, err, `value "10 KiB" cannot be represented as integer number of MiB`)
+	assert.ErrEqual(t, err, `value "10 KiB" cannot be represented as integer number of MiB (remainder: 10 KiB)`)
 
 	_, err = ParseInUnit(UnitMebibytes, "10")
 	assert.ErrEqual(t, err, `cannot convert value "10" to MiB because units are incompatible`)
@@ -33,6 +33,42 @@ func TestParseInUnit(t *testing.T) {
 
 	_, err = ParseInUnit(UnitNone, "42 MiB")
 	assert.ErrEqual(t, err, `cannot convert value "42 MiB" to <count> because units are incompatible`)
+
+	value, err = ParseInUnit(UnitKilobytes, "125 KiB")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 128)
+
+	_, err = ParseInUnit(UnitKibibytes, "1 MB")
+	assert.ErrEqual(t, err, `value "1 MB" cannot be represented as integer number of KiB (remainder: 576 B)`)
+
+	// conversions between other base units
+	value, err = ParseInUnit(UnitBits, "10 Gbit")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 10_000_000_000)
+
+	value, err = ParseInUnit(UnitSeconds, "2 h")
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 7200)
+
+	_, err = ParseInUnit(UnitBytes, "8 bit")
+	assert.ErrEqual(t, err, `cannot convert value "8 bit" to B because units are incompatible`)
+}
+
+func TestConvertToOverflow(t *testing.T) {
+	// 2^70 B overflows uint64 when converting to B, but not when converting to a larger unit
+	v := LimesV1ValueWithUnit{Value: 1 << 10, Unit: UnitExbibytes}
+	_, err := v.ConvertTo(UnitBytes)
+	assert.ErrEqual(t, err, `value "1024 EiB" cannot be represented in B without overflow`)
+
+	converted, err := v.ConvertTo(UnitTebibytes)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, converted, LimesV1ValueWithUnit{Value: 1 << 30, Unit: UnitTebibytes})
+
+	// same for conversions between prefix families
+	v = LimesV1ValueWithUnit{Value: 1 << 20, Unit: UnitExabytes}
+	converted, err = v.ConvertTo(UnitPetabytes)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, converted, LimesV1ValueWithUnit{Value: 1000 << 20, Unit: UnitPetabytes})
 }
 
 func TestValueWithUnitToString(t *testing.T) {
@@ -50,6 +86,12 @@ func TestValueWithUnitToString(t *testing.T) {
 	}
 	assert.Equal(t, v.String(), "4 MiB") // uses nice formatting, i.e. neither "128 x 32 KiB" nor "4096 KiB"
 
+	v = LimesV1ValueWithUnit{
+		Value: 42,
+		Unit:  UnitNone,
+	}
+	assert.Equal(t, v.String(), "42")
+
 	v = LimesV1ValueWithUnit{
 		// this value is equal to 2^75 bytes and overflows type Amount
 		Value: 32768,
@@ -63,4 +105,17 @@ func TestValueWithUnitToString(t *testing.T) {
 		Unit:  mustMultiply(UnitExbibytes, 2),
 	}
 	assert.Equal(t, v.String(), "16384 x 2 EiB")
+
+	// values are formatted in the prefix family of their unit
+	v = LimesV1ValueWithUnit{
+		Value: 1000,
+		Unit:  UnitBytes,
+	}
+	assert.Equal(t, v.String(), "1000 B")
+
+	v = LimesV1ValueWithUnit{
+		Value: 5000,
+		Unit:  UnitGigabytes,
+	}
+	assert.Equal(t, v.String(), "5 TB")
 }
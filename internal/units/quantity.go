@@ -0,0 +1,392 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package units
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a value with a unit, e.g. "2 GiB" or "512 MiB".
+// In contrast to LimesV1ValueWithUnit, it offers checked arithmetic across compatible units:
+//
+//	a := Quantity{Value: 2, Unit: UnitGibibytes}
+//	b := Quantity{Value: 512, Unit: UnitMebibytes}
+//	sum, err := a.Add(b) // -> Quantity{Value: 2560, Unit: UnitMebibytes}
+//
+// Units are compatible if they have the same base unit (e.g. GiB and MB are
+// compatible because both are multiples of bytes). Like in Limes, UnitNone is
+// considered to be the same as UnitPiece. When combining quantities in
+// different units, the result is expressed in the largest unit that both
+// units are a multiple of, i.e. in the least common unit.
+//
+// All operations return an error instead of silently overflowing.
+type Quantity struct {
+	Value uint64
+	Unit  Unit
+}
+
+// String implements the fmt.Stringer interface.
+// The value is serialized with the most appropriate unit, in the same way as for LimesV1ValueWithUnit.
+func (q Quantity) String() string {
+	return LimesV1ValueWithUnit(q).String()
+}
+
+// ConvertTo returns an equal quantity in the given Unit.
+// Errors are returned under the same conditions as for LimesV1ValueWithUnit.ConvertTo().
+func (q Quantity) ConvertTo(u Unit) (Quantity, error) {
+	converted, err := LimesV1ValueWithUnit(q).ConvertTo(u)
+	return Quantity(converted), err
+}
+
+// ValueInUnit returns the value of this quantity in the given unit.
+// This is used to interpret quantities that were decoded from user input,
+// where counted resources are given as bare numbers (e.g. 42), and measured
+// resources as values with unit (e.g. "10 GiB"):
+//
+//   - If this quantity is a bare number (i.e. its Unit is UnitNone), it is
+//     only accepted for counted units (i.e. UnitNone and multiples of UnitPiece).
+//     The value is interpreted as already being in the given unit.
+//   - Otherwise, the quantity is converted into the given unit in the same way as by ConvertTo().
+func (q Quantity) ValueInUnit(u Unit) (uint64, error) {
+	if q.Unit == UnitNone {
+		base, _ := u.Base()
+		if base == realUnitNone || base == UnitPiece {
+			return q.Value, nil
+		}
+		return 0, fmt.Errorf("missing unit in value %q (expected a value in %s or a compatible unit)", q.String(), u.String())
+	}
+	converted, err := q.ConvertTo(u)
+	return converted.Value, err
+}
+
+// Add returns the sum of both quantities in their least common unit.
+func (q Quantity) Add(other Quantity) (Quantity, error) {
+	lhs, rhs, err := q.toCommonUnit(other, "add")
+	if err != nil {
+		return Quantity{}, err
+	}
+	sum, carry := bits.Add64(lhs.Value, rhs.Value, 0)
+	if carry != 0 {
+		return Quantity{}, fmt.Errorf("overflow while adding %s + %s", q.String(), other.String())
+	}
+	return Quantity{Value: sum, Unit: lhs.Unit}, nil
+}
+
+// Sub returns the difference of both quantities in their least common unit.
+// Since quantities cannot be negative, an error is returned if the other quantity is larger than this one.
+func (q Quantity) Sub(other Quantity) (Quantity, error) {
+	lhs, rhs, err := q.toCommonUnit(other, "subtract")
+	if err != nil {
+		return Quantity{}, err
+	}
+	diff, borrow := bits.Sub64(lhs.Value, rhs.Value, 0)
+	if borrow != 0 {
+		return Quantity{}, fmt.Errorf("cannot subtract %s - %s because the result would be negative", q.String(), other.String())
+	}
+	return Quantity{Value: diff, Unit: lhs.Unit}, nil
+}
+
+// Mul multiplies this quantity by the given factor. The unit is not changed.
+func (q Quantity) Mul(factor uint64) (Quantity, error) {
+	hi, lo := bits.Mul64(q.Value, factor)
+	if hi != 0 {
+		return Quantity{}, fmt.Errorf("overflow while multiplying %s x %d", q.String(), factor)
+	}
+	return Quantity{Value: lo, Unit: q.Unit}, nil
+}
+
+// Div divides this quantity by the given divisor.
+// If the result cannot be represented in the same unit, it is expressed in
+// the largest fraction of that unit that can represent it exactly:
+//
+//	Quantity{Value: 6, Unit: UnitGibibytes}.Div(3) // -> Quantity{Value: 2, Unit: UnitGibibytes}
+//	Quantity{Value: 6, Unit: UnitGibibytes}.Div(4) // -> Quantity{Value: 3, Unit: <512 MiB>}
+//	Quantity{Value: 1, Unit: UnitGibibytes}.Div(4) // -> Quantity{Value: 1, Unit: <256 MiB>}
+//
+// An error is returned if the result cannot be represented as an integer number of the base unit.
+func (q Quantity) Div(divisor uint64) (Quantity, error) {
+	if divisor == 0 {
+		return Quantity{}, fmt.Errorf("cannot divide %s by zero", q.String())
+	}
+
+	// as much of the division as possible is done on the value, the rest of the divisor needs to be taken out of the unit
+	g := gcd(q.Value, divisor)
+	remainingDivisor := divisor / g
+	base, multiple := q.Unit.Base()
+	if multiple%remainingDivisor != 0 {
+		return Quantity{}, fmt.Errorf("cannot divide %s by %d without remainder", q.String(), divisor)
+	}
+	return Quantity{
+		Value: q.Value / g,
+		Unit:  multipleOfBase(base, multiple/remainingDivisor, q.Unit.family),
+	}, nil
+}
+
+// Cmp compares both quantities. The result is -1 if this quantity is smaller
+// than the other, 0 if both are equal, and +1 if this quantity is larger.
+// An error is returned if the units are incompatible.
+func (q Quantity) Cmp(other Quantity) (int, error) {
+	lhs, rhs := q.withNoneAsPiece(), other.withNoneAsPiece()
+	base, multiple := lhs.Unit.Base()
+	otherBase, otherMultiple := rhs.Unit.Base()
+	if base != otherBase {
+		return 0, incompatibleUnitsError("compare", q, other)
+	}
+
+	// compare in the base unit, using 128-bit arithmetic to avoid overflow
+	hi, lo := bits.Mul64(lhs.Value, multiple)
+	otherHi, otherLo := bits.Mul64(rhs.Value, otherMultiple)
+	switch {
+	case hi < otherHi || (hi == otherHi && lo < otherLo):
+		return -1, nil
+	case hi == otherHi && lo == otherLo:
+		return 0, nil
+	default:
+		return +1, nil
+	}
+}
+
+// Min returns the smaller of both quantities (or this one if both are equal).
+// Since no computation is necessary, the result is returned in its original unit.
+func (q Quantity) Min(other Quantity) (Quantity, error) {
+	cmp, err := q.Cmp(other)
+	if err != nil {
+		return Quantity{}, err
+	}
+	if cmp <= 0 {
+		return q, nil
+	}
+	return other, nil
+}
+
+// Max returns the larger of both quantities (or this one if both are equal).
+// Since no computation is necessary, the result is returned in its original unit.
+func (q Quantity) Max(other Quantity) (Quantity, error) {
+	cmp, err := q.Cmp(other)
+	if err != nil {
+		return Quantity{}, err
+	}
+	if cmp >= 0 {
+		return q, nil
+	}
+	return other, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Quantities with UnitNone are encoded as bare numbers, all other quantities
+// are encoded as strings like "10 GiB" (see MarshalText).
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	if q.Unit == UnitNone {
+		return json.Marshal(q.Value)
+	}
+	text, err := q.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+// The same formats as for MarshalJSON are used.
+func (q Quantity) MarshalYAML() (any, error) {
+	if q.Unit == UnitNone {
+		return q.Value, nil
+	}
+	text, err := q.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The value is written in its own unit (e.g. "1024 MiB" instead of "1 GiB"),
+// such that UnmarshalText restores the exact same Quantity. Units with a
+// numeric multiplier (e.g. "4 MiB") cannot be written in this way, so those
+// values are written in the most appropriate unit, like by String().
+func (q Quantity) MarshalText() ([]byte, error) {
+	if q.Unit == UnitNone {
+		return []byte(strconv.FormatUint(q.Value, 10)), nil
+	}
+	unitStr := q.Unit.String()
+	if !strings.Contains(unitStr, " ") {
+		return []byte(strconv.FormatUint(q.Value, 10) + " " + unitStr), nil
+	}
+	str := q.String()
+	if strings.Contains(str, " x ") {
+		// String() only produces this format if the value overflows in the base unit
+		return nil, fmt.Errorf("cannot serialize %s because the value cannot be represented in a single unit", str)
+	}
+	return []byte(str), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Bare numbers are decoded into quantities with UnitNone. Values with unit
+// must be given as strings like "10 GiB". Fractional numbers like "1.5 TiB"
+// are accepted if they can be represented exactly in the given unit or its
+// base unit.
+func (q *Quantity) UnmarshalJSON(buf []byte) error {
+	if len(buf) > 0 && buf[0] == '"' {
+		var s string
+		err := json.Unmarshal(buf, &s)
+		if err != nil {
+			return err
+		}
+		return q.UnmarshalText([]byte(s))
+	}
+
+	var value uint64
+	err := json.Unmarshal(buf, &value)
+	if err != nil {
+		return err
+	}
+	*q = Quantity{Value: value, Unit: UnitNone}
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+// The same formats as for UnmarshalJSON are accepted.
+func (q *Quantity) UnmarshalYAML(unmarshal func(any) error) error {
+	var value uint64
+	err := unmarshal(&value)
+	if err == nil {
+		*q = Quantity{Value: value, Unit: UnitNone}
+		return nil
+	}
+
+	var s string
+	err = unmarshal(&s)
+	if err != nil {
+		return err
+	}
+	return q.UnmarshalText([]byte(s))
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface used by some TOML libraries.
+// The same formats as for UnmarshalJSON are accepted.
+func (q *Quantity) UnmarshalTOML(data any) error {
+	switch data := data.(type) {
+	case int64:
+		if data < 0 {
+			return fmt.Errorf("invalid value %d: quantities cannot be negative", data)
+		}
+		*q = Quantity{Value: uint64(data), Unit: UnitNone}
+		return nil
+	case string:
+		return q.UnmarshalText([]byte(data))
+	default:
+		return fmt.Errorf("cannot unmarshal value of type %T into Quantity", data)
+	}
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The same formats as for UnmarshalJSON are accepted, except that bare numbers are given as strings.
+func (q *Quantity) UnmarshalText(text []byte) error {
+	parsed, err := parseQuantity(string(text))
+	if err == nil {
+		*q = parsed
+	}
+	return err
+}
+
+func parseQuantity(input string) (Quantity, error) {
+	fields := strings.Fields(input)
+	unit := UnitNone
+	switch len(fields) {
+	case 1:
+		// bare number
+	case 2:
+		amount, family, err := parseAmountWithFamily(fields[1], UnitOnlyFormat)
+		if err != nil {
+			return Quantity{}, fmt.Errorf("invalid value %q: no such unit", input)
+		}
+		unit = Unit{amount, normalizePrefixFamily(amount.Base, family)}
+	default:
+		desc, _ := (NumberOnlyFormat | NumberWithUnitFormat).Description()
+		return Quantity{}, fmt.Errorf(`value %q does not match any expected format (%s)`, input, desc)
+	}
+
+	if decimalNumberRx.MatchString(fields[0]) {
+		// fractional numbers are accepted if the value can be represented in the given unit or its base unit
+		value, err := parseDecimalInUnit(unit, input, fields)
+		if err != nil && unit != UnitNone {
+			unit, _ = unit.Base()
+			value, err = parseDecimalInUnit(unit, input, fields)
+		}
+		if err != nil {
+			return Quantity{}, err
+		}
+		return Quantity{Value: value, Unit: unit}, nil
+	}
+
+	value, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return Quantity{}, fmt.Errorf("invalid value %q: %w", input, err)
+	}
+	return Quantity{Value: value, Unit: unit}, nil
+}
+
+// toCommonUnit converts both quantities into their least common unit.
+// The verb is used to describe the operation in error messages.
+func (q Quantity) toCommonUnit(other Quantity, verb string) (lhs, rhs Quantity, err error) {
+	origQ, origOther := q, other
+	if (q.Unit == UnitNone) != (other.Unit == UnitNone) {
+		// when mixing UnitNone and other units, UnitNone is treated like UnitPiece (but UnitNone + UnitNone stays UnitNone)
+		q, other = q.withNoneAsPiece(), other.withNoneAsPiece()
+	}
+	base, multiple := q.Unit.Base()
+	otherBase, otherMultiple := other.Unit.Base()
+	if base != otherBase {
+		return Quantity{}, Quantity{}, incompatibleUnitsError(verb, origQ, origOther)
+	}
+
+	// keep the prefix family if both units agree on it (e.g. GB + MB -> MB, but GB + MiB -> 512 B)
+	family := noPrefixFamily
+	if q.Unit.family == other.Unit.family {
+		family = q.Unit.family
+	}
+	unit := multipleOfBase(base, gcd(multiple, otherMultiple), family)
+	lhs, err = q.ConvertTo(unit)
+	if err != nil {
+		return Quantity{}, Quantity{}, err
+	}
+	rhs, err = other.ConvertTo(unit)
+	if err != nil {
+		return Quantity{}, Quantity{}, err
+	}
+	return lhs, rhs, nil
+}
+
+// withNoneAsPiece replaces UnitNone with UnitPiece, since Limes treats both the same way.
+func (q Quantity) withNoneAsPiece() Quantity {
+	if q.Unit == UnitNone {
+		q.Unit = UnitPiece
+	}
+	return q
+}
+
+func incompatibleUnitsError(verb string, lhs, rhs Quantity) error {
+	return fmt.Errorf("cannot %s %s and %s because units are incompatible", verb, lhs.String(), rhs.String())
+}
+
+// multipleOfBase is the inverse of Unit.Base().
+// The family argument selects the prefix family used for formatting the resulting unit.
+func multipleOfBase(base Unit, multiple uint64, family prefixFamily) Unit {
+	if base == realUnitNone {
+		// values with UnitNone always have multiple = 1, but we need to return the special representation of UnitNone
+		return UnitNone
+	}
+	return Unit{amount: Amount{Base: base.amount.Base, Factor: multiple}, family: family}
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package units
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+)
+
+func TestQuantityAddSub(t *testing.T) {
+	gib2 := Quantity{Value: 2, Unit: UnitGibibytes}
+	mib512 := Quantity{Value: 512, Unit: UnitMebibytes}
+
+	sum, err := gib2.Add(mib512)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sum, Quantity{Value: 2560, Unit: UnitMebibytes})
+	assert.Equal(t, sum.String(), "2560 MiB")
+
+	diff, err := gib2.Sub(mib512)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, diff, Quantity{Value: 1536, Unit: UnitMebibytes})
+
+	_, err = mib512.Sub(gib2)
+	assert.ErrEqual(t, err, "cannot subtract 512 MiB - 2 GiB because the result would be negative")
+
+	// the least common unit of different prefix families can be a non-standard unit
+	gb1 := Quantity{Value: 1, Unit: UnitGigabytes}
+	sum, err = gib2.Add(gb1)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sum, Quantity{Value: 6147429, Unit: mustMultiply(UnitBytes, 512)})
+	assert.Equal(t, sum.String(), "3147483648 B")
+
+	// within the same prefix family, the family is kept
+	sum, err = gb1.Add(Quantity{Value: 500, Unit: UnitMegabytes})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sum, Quantity{Value: 1500, Unit: UnitMegabytes})
+	assert.Equal(t, sum.String(), "1500 MB")
+
+	// UnitNone stays UnitNone
+	sum, err = Quantity{Value: 2, Unit: UnitNone}.Add(Quantity{Value: 3, Unit: UnitNone})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sum, Quantity{Value: 5, Unit: UnitNone})
+
+	// incompatible units
+	_, err = gib2.Add(Quantity{Value: 3, Unit: UnitPiece})
+	assert.ErrEqual(t, err, "cannot add 2 GiB and 3 piece because units are incompatible")
+	_, err = Quantity{Value: 3, Unit: UnitNone}.Sub(Quantity{Value: 3, Unit: UnitSeconds})
+	assert.ErrEqual(t, err, "cannot subtract 3 and 3 s because units are incompatible")
+
+	// UnitNone is treated like UnitPiece when mixed with it
+	sum, err = Quantity{Value: 3, Unit: UnitNone}.Add(Quantity{Value: 3, Unit: UnitPiece})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sum, Quantity{Value: 6, Unit: UnitPiece})
+	diff, err = Quantity{Value: 5, Unit: mustMultiply(UnitPiece, 1000)}.Sub(Quantity{Value: 3, Unit: UnitNone})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, diff, Quantity{Value: 4997, Unit: UnitPiece})
+
+	// overflow
+	_, err = Quantity{Value: 1 << 63, Unit: UnitBytes}.Add(Quantity{Value: 8, Unit: UnitExbibytes})
+	assert.ErrEqual(t, err, "overflow while adding 8 EiB + 8 EiB")
+	_, err = Quantity{Value: 1, Unit: UnitBytes}.Add(Quantity{Value: 16, Unit: UnitExbibytes})
+	assert.ErrEqual(t, err, `value "16 EiB" cannot be represented in B without overflow`)
+}
+
+func TestQuantityMulDiv(t *testing.T) {
+	gib1 := Quantity{Value: 1, Unit: UnitGibibytes}
+
+	product, err := gib1.Mul(3)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, product, Quantity{Value: 3, Unit: UnitGibibytes})
+
+	_, err = Quantity{Value: 1 << 62, Unit: UnitBytes}.Mul(4)
+	assert.ErrEqual(t, err, "overflow while multiplying 4 EiB x 4")
+
+	quotient, err := Quantity{Value: 6, Unit: UnitGibibytes}.Div(3)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, quotient, Quantity{Value: 2, Unit: UnitGibibytes})
+
+	quotient, err = gib1.Div(4)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, quotient, Quantity{Value: 1, Unit: mustMultiply(UnitMebibytes, 256)})
+	assert.Equal(t, quotient.String(), "256 MiB")
+
+	quotient, err = Quantity{Value: 6, Unit: UnitGibibytes}.Div(4)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, quotient, Quantity{Value: 3, Unit: mustMultiply(UnitMebibytes, 512)})
+	assert.Equal(t, quotient.String(), "1536 MiB")
+
+	quotient, err = Quantity{Value: 42, Unit: UnitNone}.Div(6)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, quotient, Quantity{Value: 7, Unit: UnitNone})
+
+	_, err = gib1.Div(3)
+	assert.ErrEqual(t, err, "cannot divide 1 GiB by 3 without remainder")
+	_, err = gib1.Div(0)
+	assert.ErrEqual(t, err, "cannot divide 1 GiB by zero")
+}
+
+func TestQuantityCmpMinMax(t *testing.T) {
+	gib1 := Quantity{Value: 1, Unit: UnitGibibytes}
+	mib1024 := Quantity{Value: 1024, Unit: UnitMebibytes}
+	gb1 := Quantity{Value: 1, Unit: UnitGigabytes}
+
+	cmp, err := gib1.Cmp(mib1024)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, cmp, 0)
+	cmp, err = gib1.Cmp(gb1)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, cmp, +1)
+	cmp, err = gb1.Cmp(gib1)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, cmp, -1)
+
+	// comparison works even if the values do not fit into uint64 in the base unit
+	cmp, err = Quantity{Value: 1 << 20, Unit: UnitExbibytes}.Cmp(Quantity{Value: 1 << 30, Unit: UnitPebibytes})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, cmp, 0)
+
+	_, err = gib1.Cmp(Quantity{Value: 1, Unit: UnitSeconds})
+	assert.ErrEqual(t, err, "cannot compare 1 GiB and 1 s because units are incompatible")
+	cmp, err = Quantity{Value: 1, Unit: mustMultiply(UnitPiece, 10)}.Cmp(Quantity{Value: 10, Unit: UnitNone})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, cmp, 0)
+	_, err = Quantity{Value: 1, Unit: UnitNone}.Cmp(gib1)
+	assert.ErrEqual(t, err, "cannot compare 1 and 1 GiB because units are incompatible")
+
+	// Min and Max return the operands unchanged
+	result, err := gib1.Min(gb1)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, result, gb1)
+	result, err = gib1.Max(gb1)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, result, gib1)
+	result, err = mib1024.Min(gib1)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, result, mib1024)
+	_, err = gib1.Max(Quantity{Value: 1, Unit: UnitPiece})
+	assert.ErrEqual(t, err, "cannot compare 1 GiB and 1 piece because units are incompatible")
+}
+
+func TestQuantityValueInUnit(t *testing.T) {
+	// bare numbers are only accepted for counted units
+	bare := Quantity{Value: 42, Unit: UnitNone}
+	for _, unit := range []Unit{UnitNone, UnitPiece, mustMultiply(UnitPiece, 1000)} {
+		value, err := bare.ValueInUnit(unit)
+		assert.ErrEqual(t, err, nil)
+		assert.Equal(t, value, 42)
+	}
+	_, err := bare.ValueInUnit(UnitGibibytes)
+	assert.ErrEqual(t, err, `missing unit in value "42" (expected a value in GiB or a compatible unit)`)
+
+	// values with unit are converted
+	value, err := Quantity{Value: 5, Unit: UnitGibibytes}.ValueInUnit(UnitMebibytes)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 5120)
+	value, err = Quantity{Value: 5, Unit: UnitPiece}.ValueInUnit(UnitPiece)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, value, 5)
+	_, err = Quantity{Value: 5, Unit: UnitGibibytes}.ValueInUnit(UnitNone)
+	assert.ErrEqual(t, err, `cannot convert value "5 GiB" to <count> because units are incompatible`)
+}
+
+func TestQuantityUnmarshal(t *testing.T) {
+	test := func(jsonInput string, expected Quantity) {
+		t.Helper()
+
+		var q1 Quantity
+		assert.ErrEqual(t, json.Unmarshal([]byte(jsonInput), &q1), nil)
+		assert.Equal(t, q1, expected)
+
+		// the YAML decoder is mocked to behave like a YAML library would for the equivalent YAML input
+		var q2 Quantity
+		assert.ErrEqual(t, q2.UnmarshalYAML(func(target any) error {
+			return json.Unmarshal([]byte(jsonInput), target)
+		}), nil)
+		assert.Equal(t, q2, expected)
+
+		// the same for the TOML decoder
+		var decoded any
+		assert.ErrEqual(t, json.Unmarshal([]byte(jsonInput), &decoded), nil)
+		if number, ok := decoded.(float64); ok {
+			decoded = int64(number)
+		}
+		var q3 Quantity
+		assert.ErrEqual(t, q3.UnmarshalTOML(decoded), nil)
+		assert.Equal(t, q3, expected)
+
+		// text input always contains strings
+		var q4 Quantity
+		assert.ErrEqual(t, q4.UnmarshalText([]byte(strings.Trim(jsonInput, `"`))), nil)
+		assert.Equal(t, q4, expected)
+	}
+
+	test(`42`, Quantity{Value: 42, Unit: UnitNone})
+	test(`"42"`, Quantity{Value: 42, Unit: UnitNone})
+	test(`"10 GiB"`, Quantity{Value: 10, Unit: UnitGibibytes})
+	test(`"10 piece"`, Quantity{Value: 10, Unit: UnitPiece})
+	test(`"2.0 GiB"`, Quantity{Value: 2, Unit: UnitGibibytes})
+	test(`"1.5 GiB"`, Quantity{Value: 3 << 29, Unit: UnitBytes})
+	test(`"2.5 kbit"`, Quantity{Value: 2500, Unit: UnitBits})
+
+	// error cases
+	var q Quantity
+	assert.ErrEqual(t, q.UnmarshalText([]byte("10 bananas")), `invalid value "10 bananas": no such unit`)
+	assert.ErrEqual(t, q.UnmarshalText([]byte("ten GiB")), `invalid value "ten GiB": strconv.ParseUint: parsing "ten": invalid syntax`)
+	assert.ErrEqual(t, q.UnmarshalText([]byte("")), `value "" does not match any expected format ("<number>" or "<number> <unit>")`)
+	assert.ErrEqual(t, q.UnmarshalText([]byte("0.5 B")), `value "0.5 B" cannot be represented as integer number of B (would be 0.5 B)`)
+	assert.ErrEqual(t, q.UnmarshalText([]byte("1.5")), `value "1.5" cannot be represented as integer number of <count> (would be 1.5 <count>)`)
+	assert.ErrEqual(t, q.UnmarshalTOML(int64(-1)), `invalid value -1: quantities cannot be negative`)
+	assert.ErrEqual(t, q.UnmarshalTOML(true), `cannot unmarshal value of type bool into Quantity`)
+	assert.ErrEqual(t, q.UnmarshalYAML(func(any) error { return errors.New("malformed YAML") }), "malformed YAML")
+	if json.Unmarshal([]byte(`[1, "GiB"]`), &q) == nil {
+		t.Error("expected error when unmarshaling JSON array into Quantity")
+	}
+	assert.Equal(t, q, Quantity{}) // errors do not touch the target
+}
+
+func TestQuantityMarshalRoundTrip(t *testing.T) {
+	test := func(q Quantity, expectedText string) {
+		t.Helper()
+
+		text, err := q.MarshalText()
+		assert.ErrEqual(t, err, nil)
+		assert.Equal(t, string(text), expectedText)
+		var q1 Quantity
+		assert.ErrEqual(t, q1.UnmarshalText(text), nil)
+		assert.Equal(t, q1, q)
+
+		buf, err := json.Marshal(q)
+		assert.ErrEqual(t, err, nil)
+		if q.Unit == UnitNone {
+			assert.Equal(t, string(buf), expectedText)
+		} else {
+			assert.Equal(t, string(buf), fmt.Sprintf("%q", expectedText))
+		}
+		var q2 Quantity
+		assert.ErrEqual(t, json.Unmarshal(buf, &q2), nil)
+		assert.Equal(t, q2, q)
+
+		// the YAML encoder and decoder are mocked to behave like a YAML library would
+		yamlValue, err := q.MarshalYAML()
+		assert.ErrEqual(t, err, nil)
+		var q3 Quantity
+		assert.ErrEqual(t, q3.UnmarshalYAML(func(target any) error {
+			switch target := target.(type) {
+			case *uint64:
+				value, ok := yamlValue.(uint64)
+				if !ok {
+					return errors.New("not a number")
+				}
+				*target = value
+			case *string:
+				*target = yamlValue.(string)
+			}
+			return nil
+		}), nil)
+		assert.Equal(t, q3, q)
+	}
+
+	test(Quantity{Value: 42, Unit: UnitNone}, "42")
+	test(Quantity{Value: 10, Unit: UnitPiece}, "10 piece")
+	test(Quantity{Value: 2, Unit: UnitGibibytes}, "2 GiB")
+	test(Quantity{Value: 1024, Unit: UnitMebibytes}, "1024 MiB") // not normalized to "1 GiB"
+	test(Quantity{Value: 5, Unit: UnitTerabytes}, "5 TB")
+	test(Quantity{Value: 0, Unit: UnitBits}, "0 bit")
+
+	// in a struct (as in configuration files)
+	type config struct {
+		Quota Quantity `json:"quota"`
+	}
+	buf, err := json.Marshal(config{Quota: Quantity{Value: 2, Unit: UnitGibibytes}})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(buf), `{"quota":"2 GiB"}`)
+
+	// units with a numeric multiplier are written in the most appropriate unit instead
+	text, err := Quantity{Value: 3, Unit: mustMultiply(UnitMebibytes, 4)}.MarshalText()
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(text), "12 MiB")
+	_, err = Quantity{Value: 16384, Unit: mustMultiply(UnitExbibytes, 2)}.MarshalText()
+	assert.ErrEqual(t, err, "cannot serialize 16384 x 2 EiB because the value cannot be represented in a single unit")
+}
@@ -12,6 +12,11 @@ import (
 // Unit represents the unit a resource or rate is measured in.
 type Unit struct {
 	amount Amount
+	// The prefix family that is used when formatting this unit or values in
+	// this unit, e.g. UnitKilobytes uses decimal prefixes, so 1000 kB are
+	// formatted as "MB". This is noPrefixFamily if the default family for the
+	// base unit is used (see func normalizePrefixFamily).
+	family prefixFamily
 }
 
 var (
@@ -55,6 +60,41 @@ var (
 	UnitPebibytes = makeBytesUnit(1 << 50)
 	// UnitExbibytes is exactly that.
 	UnitExbibytes = makeBytesUnit(1 << 60)
+
+	// UnitKilobytes is exactly that.
+	UnitKilobytes = makeDecimalBytesUnit(1e3)
+	// UnitMegabytes is exactly that.
+	UnitMegabytes = makeDecimalBytesUnit(1e6)
+	// UnitGigabytes is exactly that.
+	UnitGigabytes = makeDecimalBytesUnit(1e9)
+	// UnitTerabytes is exactly that.
+	UnitTerabytes = makeDecimalBytesUnit(1e12)
+	// UnitPetabytes is exactly that.
+	UnitPetabytes = makeDecimalBytesUnit(1e15)
+	// UnitExabytes is exactly that.
+	UnitExabytes = makeDecimalBytesUnit(1e18)
+
+	// UnitBits is exactly that.
+	UnitBits = makeBitsUnit(1)
+	// UnitKilobits is exactly that.
+	UnitKilobits = makeBitsUnit(1e3)
+	// UnitMegabits is exactly that.
+	UnitMegabits = makeBitsUnit(1e6)
+	// UnitGigabits is exactly that.
+	UnitGigabits = makeBitsUnit(1e9)
+	// UnitTerabits is exactly that.
+	UnitTerabits = makeBitsUnit(1e12)
+	// UnitPetabits is exactly that.
+	UnitPetabits = makeBitsUnit(1e15)
+	// UnitExabits is exactly that.
+	UnitExabits = makeBitsUnit(1e18)
+
+	// UnitSeconds is exactly that.
+	UnitSeconds = makeSecondsUnit(1)
+	// UnitMinutes is exactly that.
+	UnitMinutes = makeSecondsUnit(60)
+	// UnitHours is exactly that.
+	UnitHours = makeSecondsUnit(3600)
 )
 
 func makeBytesUnit(factor uint64) Unit {
@@ -63,6 +103,25 @@ func makeBytesUnit(factor uint64) Unit {
 	}
 }
 
+func makeDecimalBytesUnit(factor uint64) Unit {
+	return Unit{
+		amount: Amount{Base: BaseUnitBytes, Factor: factor},
+		family: decimalPrefixFamily,
+	}
+}
+
+func makeBitsUnit(factor uint64) Unit {
+	return Unit{
+		amount: Amount{Base: BaseUnitBits, Factor: factor},
+	}
+}
+
+func makeSecondsUnit(factor uint64) Unit {
+	return Unit{
+		amount: Amount{Base: BaseUnitSeconds, Factor: factor},
+	}
+}
+
 // MultiplyBy multiplies this unit by the given factor.
 // This should only be used to construct non-standard units:
 //
@@ -85,7 +144,7 @@ func (u Unit) MultiplyBy(factor uint64) (Unit, error) {
 		panic("cannot scale UnitNone because results would not be representable with Unit's serialization rules")
 	}
 	amount, err := u.amount.MultiplyBy(factor)
-	return Unit{amount}, err
+	return Unit{amount, u.family}, err
 }
 
 const validFormatsForUnit = EmptyFormat | UnitOnlyFormat | NumberWithUnitFormat
@@ -95,11 +154,11 @@ func parseUnit(input string) (Unit, error) {
 		return UnitNone, nil
 	}
 
-	amount, err := ParseAmount(input, validFormatsForUnit)
+	amount, family, err := parseAmountWithFamily(input, validFormatsForUnit)
 	if err != nil {
 		return Unit{}, err
 	}
-	return Unit{amount}, nil
+	return Unit{amount, normalizePrefixFamily(amount.Base, family)}, nil
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -120,6 +179,36 @@ func (u Unit) MarshalJSON() ([]byte, error) {
 	return json.Marshal(u.String())
 }
 
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+// This method validates that the named unit actually exists.
+func (u *Unit) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	err := unmarshal(&s)
+	if err != nil {
+		return err
+	}
+
+	*u, err = parseUnit(s)
+	return err
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (u Unit) MarshalYAML() (any, error) {
+	return u.String(), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// This method validates that the named unit actually exists.
+func (u *Unit) UnmarshalText(text []byte) (err error) {
+	*u, err = parseUnit(string(text))
+	return err
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (u Unit) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
 // IsZero implements the Zeroer interface used by the omitzero option in encoding/json.
 func (u Unit) IsZero() bool {
 	return u == UnitNone
@@ -149,7 +238,7 @@ func (u Unit) String() string {
 	if u == UnitNone {
 		return realUnitNone.String()
 	}
-	return u.amount.Format(validFormatsForUnit)
+	return u.amount.formatInFamily(validFormatsForUnit, u.family)
 }
 
 // Base returns the base unit of this unit. For units defined as a multiple of
@@ -159,5 +248,22 @@ func (u Unit) Base() (Unit, uint64) { //nolint:gocritic // not necessary to name
 	if u == UnitNone {
 		return realUnitNone.Base()
 	}
-	return Unit{Amount{Base: u.amount.Base, Factor: 1}}, u.amount.Factor
+	return Unit{amount: Amount{Base: u.amount.Base, Factor: 1}}, u.amount.Factor
+}
+
+// IsEquivalentTo returns whether both units describe the same amount, i.e.
+// whether values in one unit can be reused in the other unit without conversion.
+// Unlike comparing with ==, this disregards differences in formatting:
+//
+//	thousandBytes, _ := UnitBytes.MultiplyBy(1000)
+//	thousandBytes == UnitKilobytes              // false ("1000 B" vs. "kB")
+//	thousandBytes.IsEquivalentTo(UnitKilobytes) // true
+func (u Unit) IsEquivalentTo(other Unit) bool {
+	if u == UnitNone {
+		u = realUnitNone
+	}
+	if other == UnitNone {
+		other = realUnitNone
+	}
+	return u.amount == other.amount
 }
@@ -6,6 +6,7 @@ package units
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -36,6 +37,21 @@ func TestParseUnit(t *testing.T) {
 			err = json.Unmarshal(buf, &u3)
 			assert.ErrEqual(t, err, nil)
 			assert.Equal(t, u3, expected)
+
+			// test parsing from text
+			var u4 Unit
+			err = u4.UnmarshalText([]byte(input))
+			assert.ErrEqual(t, err, nil)
+			assert.Equal(t, u4, expected)
+
+			// test parsing from YAML string
+			var u5 Unit
+			err = u5.UnmarshalYAML(func(target any) error {
+				*target.(*string) = input
+				return nil
+			})
+			assert.ErrEqual(t, err, nil)
+			assert.Equal(t, u5, expected)
 		})
 	}
 
@@ -45,6 +61,13 @@ func TestParseUnit(t *testing.T) {
 	test("KiB", UnitKibibytes)
 	test("1000 B", mustMultiply(UnitBytes, 1000))
 	test("1024 B", UnitKibibytes)
+	test("kB", UnitKilobytes)
+	test("1000 kB", UnitMegabytes)
+	test("GB", UnitGigabytes)
+	test("bit", UnitBits)
+	test("1000 Mbit", UnitGigabits)
+	test("60 s", UnitMinutes)
+	test("60 min", UnitHours)
 }
 
 func TestSerializeUnit(t *testing.T) {
@@ -62,6 +85,16 @@ func TestSerializeUnit(t *testing.T) {
 			buf, err := json.Marshal(unit)
 			assert.ErrEqual(t, err, nil)
 			assert.Equal(t, string(buf), fmt.Sprintf("%q", expected))
+
+			// test serialization as text
+			text, err := unit.MarshalText()
+			assert.ErrEqual(t, err, nil)
+			assert.Equal(t, string(text), expected)
+
+			// test serialization as YAML string
+			yamlValue, err := unit.MarshalYAML()
+			assert.ErrEqual(t, err, nil)
+			assert.Equal(t, yamlValue, any(expected))
 		})
 	}
 
@@ -70,9 +103,33 @@ func TestSerializeUnit(t *testing.T) {
 	test(mustMultiply(UnitPiece, 1000), "1000 piece")
 	test(UnitKibibytes, "KiB")
 	test(mustMultiply(UnitBytes, 1000), "1000 B")
+	test(mustMultiply(UnitBytes, 1001), "1001 B")
 	test(mustMultiply(UnitBytes, 1024), "KiB")
 	test(mustMultiply(UnitBytes, 2048), "2 KiB")
 	test(mustMultiply(UnitBytes, 1<<34), "16 GiB") // test that it chooses the optimal unit (not KiB or MiB)
+
+	// units without SI prefix keep using binary prefixes (even if an SI prefix would fit better)
+	test(mustMultiply(UnitBytes, 5e12), "4882812500 KiB")
+	test(mustMultiply(UnitBytes, 1000<<20), "1000 MiB")
+	test(mustMultiply(UnitBytes, 1024000), "1000 KiB")
+	// units with SI prefix stay within their prefix family
+	test(UnitKilobytes, "kB")
+	test(mustMultiply(UnitGigabytes, 5000), "5 TB")
+	test(mustMultiply(UnitKilobytes, 1024), "1024 kB")
+
+	test(UnitBits, "bit")
+	test(mustMultiply(UnitBits, 8), "8 bit")
+	test(UnitMegabits, "Mbit")
+	test(UnitSeconds, "s")
+	test(mustMultiply(UnitSeconds, 90), "90 s")
+	test(mustMultiply(UnitSeconds, 120), "2 min")
+	test(UnitHours, "h")
+}
+
+func TestParseUnitErrors(t *testing.T) {
+	var u Unit
+	assert.ErrEqual(t, u.UnmarshalText([]byte("bananas")), `invalid value "bananas": not a known unit name`)
+	assert.ErrEqual(t, u.UnmarshalYAML(func(any) error { return errors.New("malformed YAML") }), "malformed YAML")
 }
 
 func TestUnitMultiplyBy(t *testing.T) {
@@ -85,6 +142,15 @@ func TestUnitMultiplyBy(t *testing.T) {
 		assert.ErrEqual(t, err, nil)
 		assert.Equal(t, u, base)
 	}
+
+	// units that are equal in amount, but formatted differently, are not == but equivalent
+	thousandBytes := mustMultiply(UnitBytes, 1000)
+	assert.Equal(t, thousandBytes.String(), "1000 B")
+	assert.Equal(t, thousandBytes == UnitKilobytes, false)
+	assert.Equal(t, thousandBytes.IsEquivalentTo(UnitKilobytes), true)
+	assert.Equal(t, UnitKilobytes.IsEquivalentTo(UnitKibibytes), false)
+	assert.Equal(t, UnitNone.IsEquivalentTo(realUnitNone), true)
+	assert.Equal(t, UnitNone.IsEquivalentTo(UnitPiece), false)
 }
 
 func mustMultiply(unit Unit, factor uint64) Unit {
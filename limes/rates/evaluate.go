@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrates
+
+import (
+	"time"
+)
+
+// RateLimit is a limit of at most Limit events per Window.
+// A zero Window means that events are not limited at all, same as in func ProjectRateReport.EffectiveLimit.
+type RateLimit struct {
+	Limit  uint64
+	Window Window
+}
+
+// EffectiveLimit returns the rate limit that applies to this rate in this
+// project. This is the project-specific Limit and Window if those are set, or
+// the DefaultLimit and DefaultWindow otherwise. If neither is set, false is
+// returned, meaning that this rate is not limited.
+func (r ProjectRateReport) EffectiveLimit() (RateLimit, bool) {
+	if r.Window != nil && *r.Window > 0 {
+		return RateLimit{Limit: r.Limit, Window: *r.Window}, true
+	}
+	if r.DefaultWindow != nil && *r.DefaultWindow > 0 {
+		return RateLimit{Limit: r.DefaultLimit, Window: *r.DefaultWindow}, true
+	}
+	return RateLimit{}, false
+}
+
+// Decision is the result of evaluating a single event against a rate limit.
+type Decision struct {
+	Allowed bool
+	// If Allowed is false, this is how long the client needs to wait until an
+	// event would be allowed. If the limit is zero, no event will ever be
+	// allowed, so this field is zero as well.
+	RetryAfter time.Duration
+}
+
+// Evaluator is the common interface of SlidingWindowEvaluator and TokenBucketEvaluator.
+//
+// Each call to Evaluate() makes a decision on a single event that occurs at
+// the given time. Only allowed events count against the limit. Events must be
+// given in chronological order. Events that are older than an event that was
+// already evaluated are treated as if they occurred at the same time as the
+// newest event evaluated so far.
+//
+// Implementations are not safe for concurrent use.
+type Evaluator interface {
+	Evaluate(at time.Time) Decision
+}
+
+// Replay evaluates a series of events in chronological order, and returns the
+// decision for each event. This is useful to check offline what a given
+// limit would have allowed or rejected, e.g. based on audit logs.
+func Replay(e Evaluator, events []time.Time) []Decision {
+	result := make([]Decision, len(events))
+	for idx, at := range events {
+		result[idx] = e.Evaluate(at)
+	}
+	return result
+}
+
+// SlidingWindowEvaluator is an Evaluator that allows an event if less than
+// Limit events have been allowed in the Window before the event.
+//
+// This is the most precise interpretation of a rate limit, but requires
+// memory proportional to the limit, since the time of each allowed event
+// within the window needs to be remembered.
+type SlidingWindowEvaluator struct {
+	limit RateLimit
+	// times of allowed events within the window, ordered from oldest to newest
+	events []time.Time
+	latest time.Time
+}
+
+// NewSlidingWindowEvaluator creates a SlidingWindowEvaluator for the given limit.
+func NewSlidingWindowEvaluator(limit RateLimit) *SlidingWindowEvaluator {
+	return &SlidingWindowEvaluator{limit: limit}
+}
+
+// Evaluate implements the Evaluator interface.
+func (e *SlidingWindowEvaluator) Evaluate(at time.Time) Decision {
+	if at.Before(e.latest) {
+		at = e.latest
+	}
+	e.latest = at
+	if e.limit.Window == 0 {
+		return Decision{Allowed: true}
+	}
+	if e.limit.Limit == 0 {
+		return Decision{Allowed: false}
+	}
+
+	// forget events that have left the window
+	window := time.Duration(e.limit.Window) //nolint:gosec // Window values do not realistically exceed the range of time.Duration
+	for len(e.events) > 0 && !e.events[0].Add(window).After(at) {
+		e.events = e.events[1:]
+	}
+
+	if uint64(len(e.events)) >= e.limit.Limit {
+		return Decision{Allowed: false, RetryAfter: e.events[0].Add(window).Sub(at)}
+	}
+	e.events = append(e.events, at)
+	return Decision{Allowed: true}
+}
+
+// TokenBucketEvaluator is an Evaluator that implements the token bucket
+// algorithm: The bucket holds up to Limit tokens and starts out full. Each
+// allowed event consumes one token. Tokens are refilled continuously at a rate
+// of Limit tokens per Window.
+//
+// Compared to SlidingWindowEvaluator, this only requires constant memory, but
+// allows up to twice the limit within a single window in the worst case
+// (a full bucket being drained at the end of one window, and refilled
+// during the next).
+type TokenBucketEvaluator struct {
+	limit RateLimit
+	// This implements the token bucket through the equivalent "generic cell rate algorithm":
+	// Instead of counting tokens, we remember when the bucket would be full again
+	// if no further events occur. Events are allowed as long as this is not further
+	// in the future than the time it takes to refill the bucket completely.
+	fullAt time.Time
+	latest time.Time
+}
+
+// NewTokenBucketEvaluator creates a TokenBucketEvaluator for the given limit.
+func NewTokenBucketEvaluator(limit RateLimit) *TokenBucketEvaluator {
+	return &TokenBucketEvaluator{limit: limit}
+}
+
+// Evaluate implements the Evaluator interface.
+func (e *TokenBucketEvaluator) Evaluate(at time.Time) Decision {
+	if at.Before(e.latest) {
+		at = e.latest
+	}
+	e.latest = at
+	if e.limit.Window == 0 {
+		return Decision{Allowed: true}
+	}
+	if e.limit.Limit == 0 {
+		return Decision{Allowed: false}
+	}
+
+	window := time.Duration(e.limit.Window) //nolint:gosec // Window values do not realistically exceed the range of time.Duration
+	// NOTE: Time is only tracked with nanosecond precision, so limits beyond one event per nanosecond are approximated.
+	refillInterval := max(window/time.Duration(e.limit.Limit), 1) //nolint:gosec // same as above
+
+	fullAt := e.fullAt
+	if fullAt.Before(at) {
+		fullAt = at
+	}
+	newFullAt := fullAt.Add(refillInterval)
+	if excess := newFullAt.Sub(at) - window; excess > 0 {
+		return Decision{Allowed: false, RetryAfter: excess}
+	}
+	e.fullAt = newFullAt
+	return Decision{Allowed: true}
+}
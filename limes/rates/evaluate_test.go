@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrates
+
+import (
+	"testing"
+	"time"
+
+	"go.xyrillian.de/gg/assert"
+)
+
+func TestEffectiveLimit(t *testing.T) {
+	_, ok := ProjectRateReport{}.EffectiveLimit()
+	assert.Equal(t, ok, false)
+
+	report := ProjectRateReport{
+		DefaultLimit:  10,
+		DefaultWindow: new(MustParseWindow("1m")),
+	}
+	limit, ok := report.EffectiveLimit()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, limit, RateLimit{Limit: 10, Window: WindowMinutes})
+
+	report.Limit = 5
+	report.Window = new(MustParseWindow("1s"))
+	limit, ok = report.EffectiveLimit()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, limit, RateLimit{Limit: 5, Window: WindowSeconds})
+}
+
+// events at t=0s, 0.5s, 1s, 1.5s, ... 4.5s
+func testEvents() []time.Time {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	result := make([]time.Time, 10)
+	for idx := range result {
+		result[idx] = start.Add(time.Duration(idx) * 500 * time.Millisecond)
+	}
+	return result
+}
+
+func TestSlidingWindowEvaluator(t *testing.T) {
+	// 3 events per 2 seconds
+	limit := RateLimit{Limit: 3, Window: 2 * WindowSeconds}
+	decisions := Replay(NewSlidingWindowEvaluator(limit), testEvents())
+	assert.DeepEqual(t, "decisions", decisions, []Decision{
+		{Allowed: true}, // t=0s
+		{Allowed: true}, // t=0.5s
+		{Allowed: true}, // t=1s
+		{Allowed: false, RetryAfter: 500 * time.Millisecond}, // t=1.5s (event from t=0s leaves the window at t=2s)
+		{Allowed: true}, // t=2s
+		{Allowed: true}, // t=2.5s
+		{Allowed: true}, // t=3s (event from t=1s leaves the window at that exact moment)
+		{Allowed: false, RetryAfter: 500 * time.Millisecond}, // t=3.5s (event from t=2s leaves the window at t=4s)
+		{Allowed: true}, // t=4s
+		{Allowed: true}, // t=4.5s
+	})
+
+	// events out of order are treated as occurring at the latest time seen so far
+	e := NewSlidingWindowEvaluator(limit)
+	events := testEvents()
+	assert.Equal(t, e.Evaluate(events[3]), Decision{Allowed: true})
+	assert.Equal(t, e.Evaluate(events[0]), Decision{Allowed: true})
+	assert.Equal(t, e.Evaluate(events[0]), Decision{Allowed: true})
+	assert.Equal(t, e.Evaluate(events[0]), Decision{Allowed: false, RetryAfter: 2 * time.Second})
+}
+
+func TestTokenBucketEvaluator(t *testing.T) {
+	// 3 events per 2 seconds, i.e. one token every 0.667s: since events come in
+	// every 0.5s, the bucket is slowly drained until it is empty at t=4.5s
+	limit := RateLimit{Limit: 3, Window: 2 * WindowSeconds}
+	decisions := Replay(NewTokenBucketEvaluator(limit), testEvents())
+	for idx, decision := range decisions[:9] {
+		if !decision.Allowed {
+			t.Errorf("expected event %d to be allowed, but got %#v", idx, decision)
+		}
+	}
+	// at t=4.5s, 0.75 tokens are in the bucket, so we need to wait for another 0.25 token (modulo rounding errors)
+	assert.Equal(t, decisions[9], Decision{Allowed: false, RetryAfter: 166666660 * time.Nanosecond})
+
+	// 4 events per 2 seconds, i.e. one token every 0.5s
+	limit = RateLimit{Limit: 4, Window: 2 * WindowSeconds}
+	start := testEvents()[0]
+	e := NewTokenBucketEvaluator(limit)
+	for range 4 {
+		assert.Equal(t, e.Evaluate(start), Decision{Allowed: true})
+	}
+	assert.Equal(t, e.Evaluate(start), Decision{Allowed: false, RetryAfter: 500 * time.Millisecond})
+	assert.Equal(t, e.Evaluate(start.Add(250*time.Millisecond)), Decision{Allowed: false, RetryAfter: 250 * time.Millisecond})
+	// at t=1s, two tokens are back in the bucket
+	assert.Equal(t, e.Evaluate(start.Add(time.Second)), Decision{Allowed: true})
+	assert.Equal(t, e.Evaluate(start.Add(time.Second)), Decision{Allowed: true})
+	assert.Equal(t, e.Evaluate(start.Add(time.Second)), Decision{Allowed: false, RetryAfter: 500 * time.Millisecond})
+}
+
+func TestZeroLimit(t *testing.T) {
+	limit := RateLimit{Limit: 0, Window: WindowSeconds}
+	for _, e := range []Evaluator{NewSlidingWindowEvaluator(limit), NewTokenBucketEvaluator(limit)} {
+		for _, decision := range Replay(e, testEvents()) {
+			assert.Equal(t, decision, Decision{Allowed: false})
+		}
+	}
+}
+
+func TestZeroWindow(t *testing.T) {
+	for _, limit := range []RateLimit{{Limit: 0, Window: 0}, {Limit: 2, Window: 0}} {
+		for _, e := range []Evaluator{NewSlidingWindowEvaluator(limit), NewTokenBucketEvaluator(limit)} {
+			for _, decision := range Replay(e, testEvents()) {
+				assert.Equal(t, decision, Decision{Allowed: true})
+			}
+		}
+	}
+}
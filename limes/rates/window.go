@@ -4,9 +4,12 @@
 package limesrates
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math/bits"
 	"regexp"
+	"slices"
 	"strconv"
 )
 
@@ -15,8 +18,8 @@ import (
 // a limit of 10 and a window of 1 second.
 //
 // This type is very similar to time.Duration, but does not allow negative
-// values and uses a different parsing logic that does not allow phrases with
-// multiple units like time.Duration does (e.g. "2h30m").
+// values and uses a different parsing logic that supports days and weeks, but
+// no fractional numbers (e.g. "1d12h" instead of "36h" or "1.5d").
 type Window uint64
 
 const (
@@ -28,16 +31,34 @@ const (
 	WindowMinutes Window = 60 * WindowSeconds
 	// WindowHours is a Window unit.
 	WindowHours Window = 60 * WindowMinutes
+	// WindowDays is a Window unit.
+	WindowDays Window = 24 * WindowHours
+	// WindowWeeks is a Window unit.
+	WindowWeeks Window = 7 * WindowDays
 )
 
-var windowUnits = map[string]Window{
-	"ms": WindowMilliseconds,
-	"s":  WindowSeconds,
-	"m":  WindowMinutes,
-	"h":  WindowHours,
+type windowUnit struct {
+	Name       string
+	Multiplier Window
+	// Whether this unit is used by Window.String(). Days and weeks are not used
+	// there because older versions of this library cannot parse them.
+	InCanonicalForm bool
 }
 
-var windowFormatRx = regexp.MustCompile(`^\s*([0-9]+)\s*([A-Za-z]+)$`)
+// All units accepted by ParseWindow, ordered from largest to smallest.
+var windowUnits = []windowUnit{
+	{"w", WindowWeeks, false},
+	{"d", WindowDays, false},
+	{"h", WindowHours, true},
+	{"m", WindowMinutes, true},
+	{"s", WindowSeconds, true},
+	{"ms", WindowMilliseconds, true},
+}
+
+var (
+	windowFormatRx    = regexp.MustCompile(`^(?:\s*[0-9]+\s*[A-Za-z]+)+\s*$`)
+	windowComponentRx = regexp.MustCompile(`([0-9]+)\s*([A-Za-z]+)`)
+)
 
 // MustParseWindow is like ParseWindow, but panics on error. This should only be used for compile-time constants.
 func MustParseWindow(input string) Window {
@@ -49,37 +70,59 @@ func MustParseWindow(input string) Window {
 }
 
 // ParseWindow parses a string representation like "1s" or "5m".
+// Multiple components can be combined, from the largest to the smallest unit, e.g. "1h30m" or "1d 12h".
+// The available units are "ms", "s", "m", "h", "d" (24 hours) and "w" (7 days).
 func ParseWindow(input string) (Window, error) {
 	if input == "" {
 		return Window(0), nil
 	}
-
-	match := windowFormatRx.FindStringSubmatch(input)
-	if match == nil {
-		return 0, fmt.Errorf("invalid value %q: does not match expected format \"<number> <unit>\"", input)
-	}
-	number, err := strconv.ParseUint(match[1], 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid value %q: %s", input, err.Error())
+	if !windowFormatRx.MatchString(input) {
+		return 0, fmt.Errorf("invalid value %q: does not match expected format \"<number> <unit>\" (or multiple of those, e.g. \"1h30m\")", input)
 	}
-	multiplier, isValidUnit := windowUnits[match[2]]
-	if !isValidUnit {
-		return 0, fmt.Errorf("invalid value %q: unknown time unit %q", input, match[2])
+
+	var (
+		result      Window
+		prevUnitIdx = -1
+	)
+	for _, match := range windowComponentRx.FindAllStringSubmatch(input, -1) {
+		number, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q: %s", input, err.Error())
+		}
+		unitIdx := slices.IndexFunc(windowUnits, func(u windowUnit) bool { return u.Name == match[2] })
+		if unitIdx == -1 {
+			return 0, fmt.Errorf("invalid value %q: unknown time unit %q", input, match[2])
+		}
+		if unitIdx <= prevUnitIdx {
+			return 0, fmt.Errorf("invalid value %q: time units must appear in descending order and only once each", input)
+		}
+		prevUnitIdx = unitIdx
+
+		hi, value := bits.Mul64(number, uint64(windowUnits[unitIdx].Multiplier))
+		sum, carry := bits.Add64(uint64(result), value, 0)
+		if hi != 0 || carry != 0 {
+			return 0, fmt.Errorf("invalid value %q: value is too large", input)
+		}
+		result = Window(sum)
 	}
-	return Window(number) * multiplier, nil
+	return result, nil
 }
 
 // String returns the optimal string representation for this window.
+// This is always a single number with a single unit, using only the units up to hours.
+// If the window is not an exact multiple of one millisecond, an empty string is returned.
 func (w Window) String() string {
 	if w == 0 {
 		return ""
 	}
 
 	// find the unit that yields the shortest exact representation
+	// (ties cannot occur because the units are ordered from largest to smallest,
+	// and smaller units always result in a longer number)
 	shortest := ""
-	for unit, multiplier := range windowUnits {
-		if w%multiplier == 0 {
-			repr := fmt.Sprintf("%d%s", uint64(w/multiplier), unit)
+	for _, unit := range windowUnits {
+		if unit.InCanonicalForm && w%unit.Multiplier == 0 {
+			repr := fmt.Sprintf("%d%s", uint64(w/unit.Multiplier), unit.Name)
 			if shortest == "" || len(shortest) > len(repr) {
 				shortest = repr
 			}
@@ -88,13 +131,23 @@ func (w Window) String() string {
 	return shortest
 }
 
+// Like String, but returns an error if the window cannot be represented exactly.
+func (w Window) marshalString() (string, error) {
+	repr := w.String()
+	if w != 0 && repr == "" {
+		return "", fmt.Errorf("unrepresentable window size: %d ns", uint64(w))
+	}
+	return repr, nil
+}
+
 // MarshalJSON implements the json.Marshaler interface.
+// Like in all other serializations, a zero window is represented as an empty string.
 func (w Window) MarshalJSON() ([]byte, error) {
-	repr := w.String()
-	if repr == "" {
-		return nil, fmt.Errorf("unrepresentable window size: %d ns", uint64(w))
+	repr, err := w.marshalString()
+	if err != nil {
+		return nil, err
 	}
-	return fmt.Appendf(nil, "%q", repr), nil
+	return json.Marshal(repr)
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -125,3 +178,59 @@ func (w *Window) UnmarshalYAML(unmarshal func(any) error) error {
 	}
 	return err
 }
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (w Window) MarshalYAML() (any, error) {
+	return w.marshalString()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+//
+// Since encoding/json uses this method for map keys, a map[Window]T is
+// serialized into JSON with keys like "1h" instead of numbers of nanoseconds.
+func (w Window) MarshalText() (text []byte, err error) {
+	repr, err := w.marshalString()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(repr), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (w *Window) UnmarshalText(text []byte) error {
+	win, err := ParseWindow(string(text))
+	if err == nil {
+		*w = win
+	}
+	return err
+}
+
+// Scan implements the sql.Scanner interface.
+func (w *Window) Scan(src any) error {
+	var srcString string
+	switch src := src.(type) {
+	case string:
+		srcString = src
+	case []byte:
+		srcString = string(src)
+	case nil:
+		srcString = ""
+	default:
+		return fmt.Errorf("cannot scan value of type %T into type limesrates.Window", src)
+	}
+
+	win, err := ParseWindow(srcString)
+	if err == nil {
+		*w = win
+	}
+	return err
+}
+
+// Value implements the sql/driver.Valuer interface.
+func (w Window) Value() (driver.Value, error) {
+	repr, err := w.marshalString()
+	if err != nil {
+		return nil, err
+	}
+	return driver.Value(repr), nil
+}
@@ -3,7 +3,12 @@
 
 package limesrates
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+)
 
 func TestWindowSerializeRoundtrip(t *testing.T) {
 	tests := map[string]string{
@@ -20,6 +25,15 @@ func TestWindowSerializeRoundtrip(t *testing.T) {
 		"120m":   "2h",
 		"1h":     "1h",
 		"120h":   "120h",
+		// days, weeks and compound expressions are accepted, but not generated
+		"1d":         "24h",
+		"2w":         "336h",
+		"1h30m":      "90m",
+		"1h 30m":     "90m",
+		" 1d 12h ":   "36h",
+		"1m30s":      "90s",
+		"1s500ms":    "1500ms",
+		"1w1d1h1m1s": "694861s",
 	}
 
 	for input, expected := range tests {
@@ -34,3 +48,94 @@ func TestWindowSerializeRoundtrip(t *testing.T) {
 		}
 	}
 }
+
+func TestWindowParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"1":        `invalid value "1": does not match expected format "<number> <unit>" (or multiple of those, e.g. "1h30m")`,
+		"1.5h":     `invalid value "1.5h": does not match expected format "<number> <unit>" (or multiple of those, e.g. "1h30m")`,
+		"-1h":      `invalid value "-1h": does not match expected format "<number> <unit>" (or multiple of those, e.g. "1h30m")`,
+		"1y":       `invalid value "1y": unknown time unit "y"`,
+		"30m1h":    `invalid value "30m1h": time units must appear in descending order and only once each`,
+		"1h1h":     `invalid value "1h1h": time units must appear in descending order and only once each`,
+		"30501w":   `invalid value "30501w": value is too large`,
+		"30500w6d": `invalid value "30500w6d": value is too large`,
+	}
+
+	for input, expected := range tests {
+		_, err := ParseWindow(input)
+		assert.ErrEqual(t, err, expected)
+	}
+}
+
+func TestWindowMarshal(t *testing.T) {
+	w := MustParseWindow("1d12h")
+
+	buf, err := json.Marshal(w)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(buf), `"36h"`)
+
+	yamlValue, err := w.MarshalYAML()
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, yamlValue, any("36h"))
+
+	text, err := w.MarshalText()
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(text), "36h")
+
+	dbValue, err := w.Value()
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, dbValue, any("36h"))
+
+	// windows that are not a multiple of one millisecond cannot be represented
+	_, err = Window(1500).MarshalText()
+	assert.ErrEqual(t, err, "unrepresentable window size: 1500 ns")
+	_, err = Window(1500).Value()
+	assert.ErrEqual(t, err, "unrepresentable window size: 1500 ns")
+
+	_, err = Window(1500).MarshalJSON()
+	assert.ErrEqual(t, err, "unrepresentable window size: 1500 ns")
+
+	// zero is represented as the empty string
+	text, err = Window(0).MarshalText()
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(text), "")
+	buf, err = json.Marshal(Window(0))
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(buf), `""`)
+
+	// map keys use the string representation instead of numbers of nanoseconds
+	buf, err = json.Marshal(map[Window]uint64{WindowHours: 10, 90 * WindowSeconds: 5})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(buf), `{"1h":10,"90s":5}`)
+	var decoded map[Window]uint64
+	assert.ErrEqual(t, json.Unmarshal(buf, &decoded), nil)
+	assert.DeepEqual(t, "decoded map", decoded, map[Window]uint64{WindowHours: 10, 90 * WindowSeconds: 5})
+}
+
+func TestWindowUnmarshal(t *testing.T) {
+	var w Window
+	assert.ErrEqual(t, json.Unmarshal([]byte(`"2w"`), &w), nil)
+	assert.Equal(t, w, 2*WindowWeeks)
+
+	assert.ErrEqual(t, w.UnmarshalYAML(func(target any) error {
+		*target.(*string) = "1h30m"
+		return nil
+	}), nil)
+	assert.Equal(t, w, 90*WindowMinutes)
+
+	assert.ErrEqual(t, w.UnmarshalText([]byte("1d")), nil)
+	assert.Equal(t, w, WindowDays)
+
+	assert.ErrEqual(t, w.Scan("5s"), nil)
+	assert.Equal(t, w, 5*WindowSeconds)
+	assert.ErrEqual(t, w.Scan([]byte("1m")), nil)
+	assert.Equal(t, w, WindowMinutes)
+	assert.ErrEqual(t, w.Scan(nil), nil)
+	assert.Equal(t, w, Window(0))
+	assert.ErrEqual(t, w.Scan(42), "cannot scan value of type int into type limesrates.Window")
+
+	// on error, the previous value is retained
+	w = WindowHours
+	assert.ErrEqual(t, w.UnmarshalText([]byte("1y")), `invalid value "1y": unknown time unit "y"`)
+	assert.Equal(t, w, WindowHours)
+}
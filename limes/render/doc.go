@@ -0,0 +1,18 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package limesrender renders the reports from the Limes resource and rate APIs
+// as human-readable tables. It is intended as a shared presentation layer for
+// CLIs and chat bots that display Limes data.
+//
+// Each report type has a function that converts it into a [Table], e.g.
+// [ProjectResources] for a [limesresources.ProjectReport]. The resulting table
+// can then be written in any of the supported formats:
+//
+//	table := limesrender.ProjectResources(report, limesrender.Options{PerAZ: true})
+//	err := table.Write(os.Stdout, limesrender.TextFormat)
+//
+// Values are displayed with their unit as per [limes.ValueWithUnit], e.g. "5 GiB"
+// instead of the raw integer. Rows are always sorted by service type and
+// resource (or rate) name, so that output is stable across invocations.
+package limesrender
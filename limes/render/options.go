@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrender
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sapcc/go-api-declarations/limes"
+)
+
+// Options controls which parts of a report are rendered into a Table.
+type Options struct {
+	// If not empty, only services with one of these types are shown.
+	ServiceTypes []limes.ServiceType
+	// If not empty, only services in one of these areas are shown.
+	Areas []string
+	// If not empty, only resources in one of these categories are shown.
+	// This filter is ignored for rate reports, since rates do not have categories.
+	Categories []string
+	// If true, additional columns are shown for each availability zone that
+	// appears in the report. This option is ignored for rate reports, since
+	// rates are not broken down by AZ.
+	PerAZ bool
+}
+
+func (o Options) includesService(info limes.ServiceInfo) bool {
+	if len(o.ServiceTypes) > 0 && !slices.Contains(o.ServiceTypes, info.Type) {
+		return false
+	}
+	if len(o.Areas) > 0 && !slices.Contains(o.Areas, info.Area) {
+		return false
+	}
+	return true
+}
+
+func (o Options) includesCategory(category string) bool {
+	return len(o.Categories) == 0 || slices.Contains(o.Categories, category)
+}
+
+// formatValue renders a value in the given unit, e.g. "5 GiB".
+func formatValue(value uint64, unit limes.Unit) string {
+	return limes.ValueWithUnit{Value: value, Unit: unit}.String()
+}
+
+// formatOptionalValue is like formatValue, but renders nil as an empty cell.
+func formatOptionalValue(value *uint64, unit limes.Unit) string {
+	if value == nil {
+		return ""
+	}
+	return formatValue(*value, unit)
+}
+
+// formatBigValue is like formatValue, but takes a decimal string representation
+// of a value that might not fit into uint64.
+func formatBigValue(value string, unit limes.Unit) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err == nil {
+		return formatValue(parsed, unit)
+	}
+	unitStr := unit.String()
+	switch {
+	case unitStr == "":
+		return value
+	case strings.Contains(unitStr, " "): // unit has a numeric multiplier by itself, e.g. "4 MiB"
+		return value + " x " + unitStr
+	default:
+		return value + " " + unitStr
+	}
+}
+
+// sortAZs sorts a list of AZs for display, with the pseudo-AZs "any" and "unknown" at the end.
+func sortAZs(azs []limes.AvailabilityZone) []limes.AvailabilityZone {
+	rank := func(az limes.AvailabilityZone) int {
+		switch az {
+		case limes.AvailabilityZoneAny:
+			return 1
+		case limes.AvailabilityZoneUnknown:
+			return 2
+		default:
+			return 0
+		}
+	}
+	slices.SortFunc(azs, func(lhs, rhs limes.AvailabilityZone) int {
+		return cmp.Or(cmp.Compare(rank(lhs), rank(rhs)), cmp.Compare(lhs, rhs))
+	})
+	return slices.Compact(azs)
+}
+
+func valueColumn(title string) Column {
+	return Column{Title: title, RightAligned: true}
+}
+
+func azColumn(az limes.AvailabilityZone, title string) Column {
+	return valueColumn(string(az) + " " + title)
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrender
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/sapcc/go-api-declarations/limes"
+	limesrates "github.com/sapcc/go-api-declarations/limes/rates"
+)
+
+// formatRateLimit renders a rate limit like "5 GiB/1h", or as an empty cell if there is no limit.
+func formatRateLimit(limit uint64, window *limesrates.Window, unit limes.Unit) string {
+	if limit == 0 && (window == nil || *window == 0) {
+		return ""
+	}
+	result := formatValue(limit, unit)
+	if window != nil && *window != 0 {
+		result += "/" + window.String()
+	}
+	return result
+}
+
+// ProjectRates renders a project report from the Limes rate API.
+// The "limit" column only shows limits that were specifically configured for
+// this project. The limits that apply otherwise are in the "default limit" column.
+func ProjectRates(report limesrates.ProjectReport, opts Options) Table {
+	table := Table{Columns: []Column{
+		{Title: "service"},
+		{Title: "rate"},
+		valueColumn("limit"),
+		valueColumn("default limit"),
+		valueColumn("usage"),
+	}}
+
+	for _, serviceType := range slices.Sorted(maps.Keys(report.Services)) {
+		service := report.Services[serviceType]
+		if !opts.includesService(service.ServiceInfo) {
+			continue
+		}
+		for _, rateName := range slices.Sorted(maps.Keys(service.Rates)) {
+			rate := service.Rates[rateName]
+			table.Rows = append(table.Rows, []string{
+				string(serviceType),
+				string(rate.Name),
+				formatRateLimit(rate.Limit, rate.Window, rate.Unit),
+				formatRateLimit(rate.DefaultLimit, rate.DefaultWindow, rate.Unit),
+				formatBigValue(rate.UsageAsBigint, rate.Unit),
+			})
+		}
+	}
+	return table
+}
+
+// ClusterRates renders a cluster report from the Limes rate API.
+func ClusterRates(report limesrates.ClusterReport, opts Options) Table {
+	table := Table{Columns: []Column{
+		{Title: "service"},
+		{Title: "rate"},
+		valueColumn("limit"),
+	}}
+
+	for _, serviceType := range slices.Sorted(maps.Keys(report.Services)) {
+		service := report.Services[serviceType]
+		if !opts.includesService(service.ServiceInfo) {
+			continue
+		}
+		for _, rateName := range slices.Sorted(maps.Keys(service.Rates)) {
+			rate := service.Rates[rateName]
+			table.Rows = append(table.Rows, []string{
+				string(serviceType),
+				string(rate.Name),
+				formatRateLimit(rate.Limit, &rate.Window, rate.Unit),
+			})
+		}
+	}
+	return table
+}
@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrender
+
+import (
+	"strings"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/go-api-declarations/limes"
+	limesrates "github.com/sapcc/go-api-declarations/limes/rates"
+	limesresources "github.com/sapcc/go-api-declarations/limes/resources"
+)
+
+func checkTextTable(t *testing.T, table Table, expected string) {
+	t.Helper()
+	var sb strings.Builder
+	err := table.Write(&sb, TextFormat)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sb.String(), strings.TrimPrefix(expected, "\n"))
+}
+
+var projectReport = limesresources.ProjectReport{
+	ProjectInfo: limes.ProjectInfo{UUID: "uuid-for-berlin", Name: "berlin"},
+	Services: limesresources.ProjectServiceReports{
+		"shared": &limesresources.ProjectServiceReport{
+			ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+			Resources: limesresources.ProjectResourceReports{
+				"things": &limesresources.ProjectResourceReport{
+					ResourceInfo: limesresources.ResourceInfo{Name: "things"},
+					PerAZ: limesresources.ProjectAZResourceReports{
+						limes.AvailabilityZoneAny: {Usage: 2},
+					},
+					Quota: new(uint64(10)),
+					Usage: 2,
+				},
+				"capacity": &limesresources.ProjectResourceReport{
+					ResourceInfo: limesresources.ResourceInfo{Name: "capacity", Unit: limes.UnitMebibytes, Category: "storage"},
+					PerAZ: limesresources.ProjectAZResourceReports{
+						"az-two": {Quota: new(uint64(1024)), Usage: 512},
+						"az-one": {Quota: new(uint64(2048)), Usage: 1536, PhysicalUsage: new(uint64(1024))},
+					},
+					Quota:         new(uint64(3072)),
+					Usage:         2048,
+					PhysicalUsage: new(uint64(1536)),
+				},
+			},
+		},
+		"compute": &limesresources.ProjectServiceReport{
+			ServiceInfo: limes.ServiceInfo{Type: "compute", Area: "compute"},
+			Resources: limesresources.ProjectResourceReports{
+				"cores": &limesresources.ProjectResourceReport{
+					ResourceInfo: limesresources.ResourceInfo{Name: "cores"},
+					Quota:        new(uint64(40)),
+					Usage:        12,
+				},
+			},
+		},
+	},
+}
+
+func TestProjectResources(t *testing.T) {
+	checkTextTable(t, ProjectResources(projectReport, Options{}), `
+service  resource  quota  usage  physical usage
+compute  cores        40     12
+shared   capacity  3 GiB  2 GiB        1536 MiB
+shared   things       10      2
+`)
+
+	checkTextTable(t, ProjectResources(projectReport, Options{Areas: []string{"shared"}, PerAZ: true}), `
+service  resource  quota  usage  physical usage  az-one quota  az-one usage  az-two quota  az-two usage  any quota  any usage
+shared   capacity  3 GiB  2 GiB        1536 MiB         2 GiB      1536 MiB         1 GiB       512 MiB
+shared   things       10      2                                                                                             2
+`)
+
+	checkTextTable(t, ProjectResources(projectReport, Options{ServiceTypes: []limes.ServiceType{"shared"}, Categories: []string{"storage"}}), `
+service  resource  quota  usage  physical usage
+shared   capacity  3 GiB  2 GiB        1536 MiB
+`)
+}
+
+func TestDomainResources(t *testing.T) {
+	report := limesresources.DomainReport{
+		DomainInfo: limes.DomainInfo{UUID: "uuid-for-germany", Name: "germany"},
+		Services: limesresources.DomainServiceReports{
+			"shared": &limesresources.DomainServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+				Resources: limesresources.DomainResourceReports{
+					"capacity": &limesresources.DomainResourceReport{
+						ResourceInfo: limesresources.ResourceInfo{Name: "capacity", Unit: limes.UnitGibibytes},
+						PerAZ: limesresources.DomainAZResourceReports{
+							"az-one": {Usage: 1024},
+							"az-two": {Usage: 20},
+						},
+						DomainQuota:   new(uint64(2048)),
+						ProjectsQuota: new(uint64(1536)),
+						Usage:         1044,
+					},
+				},
+			},
+		},
+	}
+
+	checkTextTable(t, DomainResources(report, Options{PerAZ: true}), `
+service  resource  quota  projects quota     usage  physical usage  az-one usage  az-two usage
+shared   capacity  2 TiB        1536 GiB  1044 GiB                         1 TiB        20 GiB
+`)
+}
+
+func TestClusterResources(t *testing.T) {
+	report := limesresources.ClusterReport{
+		ClusterInfo: limes.ClusterInfo{ID: "current"},
+		Services: limesresources.ClusterServiceReports{
+			"shared": &limesresources.ClusterServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+				Resources: limesresources.ClusterResourceReports{
+					"capacity": &limesresources.ClusterResourceReport{
+						ResourceInfo: limesresources.ResourceInfo{Name: "capacity", Unit: limes.UnitBytes},
+						Capacity:     new(uint64(3 << 40)),
+						PerAZ: limesresources.ClusterAZResourceReports{
+							"az-one": {Capacity: 2 << 40, ProjectsUsage: 1 << 40},
+							"az-two": {Capacity: 1 << 40, ProjectsUsage: 1 << 39},
+						},
+						DomainsQuota: new(uint64(2 << 40)),
+						Usage:        3 << 39,
+					},
+					"things": &limesresources.ClusterResourceReport{
+						ResourceInfo: limesresources.ResourceInfo{Name: "things"},
+						Capacity:     new(uint64(100)),
+						CapacityPerAZ: limesresources.ClusterAvailabilityZoneReports{
+							"az-one": {Name: "az-one", Capacity: 60, Usage: 12},
+							"az-two": {Name: "az-two", Capacity: 40, Usage: 8},
+						},
+						Usage: 20,
+					},
+				},
+			},
+		},
+	}
+
+	checkTextTable(t, ClusterResources(report, Options{PerAZ: true}), `
+service  resource  capacity  domains quota     usage  physical usage  az-one capacity  az-one usage  az-two capacity  az-two usage
+shared   capacity     3 TiB          2 TiB  1536 GiB                            2 TiB         1 TiB            1 TiB       512 GiB
+shared   things         100                       20                               60            12               40             8
+`)
+}
+
+func TestRates(t *testing.T) {
+	projectReport := limesrates.ProjectReport{
+		ProjectInfo: limes.ProjectInfo{UUID: "uuid-for-berlin", Name: "berlin"},
+		Services: limesrates.ProjectServiceReports{
+			"shared": &limesrates.ProjectServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+				Rates: limesrates.ProjectRateReports{
+					"service/shared/objects:create": &limesrates.ProjectRateReport{
+						RateInfo:      limesrates.RateInfo{Name: "service/shared/objects:create"},
+						Limit:         5,
+						Window:        new(limesrates.MustParseWindow("1m")),
+						DefaultLimit:  10,
+						DefaultWindow: new(limesrates.MustParseWindow("1h")),
+						UsageAsBigint: "1234",
+					},
+					"data_transfer": &limesrates.ProjectRateReport{
+						RateInfo:      limesrates.RateInfo{Name: "data_transfer", Unit: limes.UnitMebibytes},
+						UsageAsBigint: "123456789012345678901234",
+					},
+					"uploads": &limesrates.ProjectRateReport{
+						RateInfo:      limesrates.RateInfo{Name: "uploads", Unit: limes.UnitKibibytes},
+						DefaultLimit:  2048,
+						DefaultWindow: new(limesrates.MustParseWindow("1s")),
+						UsageAsBigint: "1048576",
+					},
+				},
+			},
+		},
+	}
+	checkTextTable(t, ProjectRates(projectReport, Options{}), `
+service  rate                           limit  default limit                         usage
+shared   data_transfer                                        123456789012345678901234 MiB
+shared   service/shared/objects:create   5/1m          10/1h                          1234
+shared   uploads                                    2 MiB/1s                         1 GiB
+`)
+
+	clusterReport := limesrates.ClusterReport{
+		ClusterInfo: limes.ClusterInfo{ID: "current"},
+		Services: limesrates.ClusterServiceReports{
+			"shared": &limesrates.ClusterServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+				Rates: limesrates.ClusterRateReports{
+					"service/shared/objects:create": &limesrates.ClusterRateReport{
+						RateInfo: limesrates.RateInfo{Name: "service/shared/objects:create"},
+						Limit:    10,
+						Window:   limesrates.MustParseWindow("1h"),
+					},
+					"data_transfer": &limesrates.ClusterRateReport{
+						RateInfo: limesrates.RateInfo{Name: "data_transfer", Unit: limes.UnitMebibytes},
+					},
+				},
+			},
+			"compute": &limesrates.ClusterServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "compute", Area: "compute"},
+				Rates: limesrates.ClusterRateReports{
+					"instances:create": &limesrates.ClusterRateReport{
+						RateInfo: limesrates.RateInfo{Name: "instances:create"},
+						Limit:    1,
+						Window:   limesrates.MustParseWindow("1s"),
+					},
+				},
+			},
+		},
+	}
+	checkTextTable(t, ClusterRates(clusterReport, Options{}), `
+service  rate                           limit
+compute  instances:create                1/1s
+shared   data_transfer
+shared   service/shared/objects:create  10/1h
+`)
+	checkTextTable(t, ClusterRates(clusterReport, Options{ServiceTypes: []limes.ServiceType{"compute"}}), `
+service  rate              limit
+compute  instances:create   1/1s
+`)
+}
@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrender
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/sapcc/go-api-declarations/limes"
+	limesresources "github.com/sapcc/go-api-declarations/limes/resources"
+)
+
+// resourceTable is the common representation of project, domain and cluster
+// resource reports. The report-specific functions only decide which values go
+// into which columns. Filtering, sorting and the layout of the per-AZ columns
+// is done in func render(), so that it works the same on all levels.
+type resourceTable struct {
+	// Titles of the columns following the "service" and "resource" columns.
+	Columns []string
+	// Columns shown for each AZ if Options.PerAZ is set.
+	AZColumns []resourceAZColumn
+	Rows      []resourceRow
+}
+
+// resourceAZColumn appears in type resourceTable.
+type resourceAZColumn struct {
+	Title string
+	// If true, this column is only shown if at least one of the displayed rows has a value in it.
+	OmitIfEmpty bool
+}
+
+// resourceRow appears in type resourceTable.
+type resourceRow struct {
+	ServiceType limes.ServiceType
+	Service     limes.ServiceInfo
+	Resource    limesresources.ResourceName
+	Category    string
+	// Cells for resourceTable.Columns.
+	Cells []string
+	// Cells for resourceTable.AZColumns. If an AZ is missing, its cells are left empty.
+	CellsPerAZ map[limes.AvailabilityZone][]string
+}
+
+func (rt resourceTable) render(opts Options) Table {
+	rows := slices.DeleteFunc(slices.Clone(rt.Rows), func(row resourceRow) bool {
+		return !opts.includesService(row.Service) || !opts.includesCategory(row.Category)
+	})
+	slices.SortStableFunc(rows, func(lhs, rhs resourceRow) int {
+		return cmp.Or(cmp.Compare(lhs.ServiceType, rhs.ServiceType), cmp.Compare(lhs.Resource, rhs.Resource))
+	})
+
+	// choose which AZ columns to show
+	var (
+		azs          []limes.AvailabilityZone
+		azColumnIdxs []int
+	)
+	if opts.PerAZ {
+		for _, row := range rows {
+			for az := range row.CellsPerAZ {
+				azs = append(azs, az)
+			}
+		}
+		azs = sortAZs(azs)
+
+		for idx, column := range rt.AZColumns {
+			isShown := !column.OmitIfEmpty
+			for _, row := range rows {
+				for _, cells := range row.CellsPerAZ {
+					isShown = isShown || cells[idx] != ""
+				}
+			}
+			if isShown {
+				azColumnIdxs = append(azColumnIdxs, idx)
+			}
+		}
+	}
+
+	table := Table{Columns: []Column{{Title: "service"}, {Title: "resource"}}}
+	for _, title := range rt.Columns {
+		table.Columns = append(table.Columns, valueColumn(title))
+	}
+	for _, az := range azs {
+		for _, idx := range azColumnIdxs {
+			table.Columns = append(table.Columns, azColumn(az, rt.AZColumns[idx].Title))
+		}
+	}
+
+	for _, row := range rows {
+		cells := append([]string{string(row.ServiceType), string(row.Resource)}, row.Cells...)
+		for _, az := range azs {
+			azCells := row.CellsPerAZ[az]
+			for _, idx := range azColumnIdxs {
+				if azCells == nil {
+					cells = append(cells, "")
+				} else {
+					cells = append(cells, azCells[idx])
+				}
+			}
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
+
+// ProjectResources renders a project report from the Limes resource API.
+//
+// If opts.PerAZ is set, usage is shown for each AZ. Quota is additionally
+// shown for each AZ if at least one resource has per-AZ quota.
+func ProjectResources(report limesresources.ProjectReport, opts Options) Table {
+	rt := resourceTable{
+		Columns:   []string{"quota", "usage", "physical usage"},
+		AZColumns: []resourceAZColumn{{Title: "quota", OmitIfEmpty: true}, {Title: "usage"}},
+	}
+	for serviceType, service := range report.Services {
+		for _, res := range service.Resources {
+			row := resourceRow{
+				ServiceType: serviceType,
+				Service:     service.ServiceInfo,
+				Resource:    res.Name,
+				Category:    res.Category,
+				Cells: []string{
+					formatOptionalValue(res.Quota, res.Unit),
+					formatValue(res.Usage, res.Unit),
+					formatOptionalValue(res.PhysicalUsage, res.Unit),
+				},
+				CellsPerAZ: make(map[limes.AvailabilityZone][]string, len(res.PerAZ)),
+			}
+			for az, azReport := range res.PerAZ {
+				row.CellsPerAZ[az] = []string{
+					formatOptionalValue(azReport.Quota, res.Unit),
+					formatValue(azReport.Usage, res.Unit),
+				}
+			}
+			rt.Rows = append(rt.Rows, row)
+		}
+	}
+	return rt.render(opts)
+}
+
+// DomainResources renders a domain report from the Limes resource API.
+//
+// If opts.PerAZ is set, usage is shown for each AZ. Quota is additionally
+// shown for each AZ if at least one resource has per-AZ quota.
+func DomainResources(report limesresources.DomainReport, opts Options) Table {
+	rt := resourceTable{
+		Columns:   []string{"quota", "projects quota", "usage", "physical usage"},
+		AZColumns: []resourceAZColumn{{Title: "quota", OmitIfEmpty: true}, {Title: "usage"}},
+	}
+	for serviceType, service := range report.Services {
+		for _, res := range service.Resources {
+			row := resourceRow{
+				ServiceType: serviceType,
+				Service:     service.ServiceInfo,
+				Resource:    res.Name,
+				Category:    res.Category,
+				Cells: []string{
+					formatOptionalValue(res.DomainQuota, res.Unit),
+					formatOptionalValue(res.ProjectsQuota, res.Unit),
+					formatValue(res.Usage, res.Unit),
+					formatOptionalValue(res.PhysicalUsage, res.Unit),
+				},
+				CellsPerAZ: make(map[limes.AvailabilityZone][]string, len(res.PerAZ)),
+			}
+			for az, azReport := range res.PerAZ {
+				row.CellsPerAZ[az] = []string{
+					formatOptionalValue(azReport.Quota, res.Unit),
+					formatValue(azReport.Usage, res.Unit),
+				}
+			}
+			rt.Rows = append(rt.Rows, row)
+		}
+	}
+	return rt.render(opts)
+}
+
+// ClusterResources renders a cluster report from the Limes resource API.
+//
+// If opts.PerAZ is set, capacity and usage are shown for each AZ. The per-AZ
+// usage is the usage aggregated across all projects, i.e. the ProjectsUsage
+// field in PerAZ. For reports that only contain the older CapacityPerAZ
+// breakdown, that breakdown is shown instead.
+func ClusterResources(report limesresources.ClusterReport, opts Options) Table {
+	rt := resourceTable{
+		Columns:   []string{"capacity", "domains quota", "usage", "physical usage"},
+		AZColumns: []resourceAZColumn{{Title: "capacity"}, {Title: "usage"}},
+	}
+	for serviceType, service := range report.Services {
+		for _, res := range service.Resources {
+			row := resourceRow{
+				ServiceType: serviceType,
+				Service:     service.ServiceInfo,
+				Resource:    res.Name,
+				Category:    res.Category,
+				Cells: []string{
+					formatOptionalValue(res.Capacity, res.Unit),
+					formatOptionalValue(res.DomainsQuota, res.Unit),
+					formatValue(res.Usage, res.Unit),
+					formatOptionalValue(res.PhysicalUsage, res.Unit),
+				},
+				CellsPerAZ: make(map[limes.AvailabilityZone][]string, len(res.PerAZ)+len(res.CapacityPerAZ)),
+			}
+			for az, azReport := range res.CapacityPerAZ {
+				row.CellsPerAZ[az] = []string{
+					formatValue(azReport.Capacity, res.Unit),
+					formatValue(azReport.Usage, res.Unit),
+				}
+			}
+			// the newer PerAZ breakdown takes precedence over CapacityPerAZ
+			for az, azReport := range res.PerAZ {
+				row.CellsPerAZ[az] = []string{
+					formatValue(azReport.Capacity, res.Unit),
+					formatValue(azReport.ProjectsUsage, res.Unit),
+				}
+			}
+			rt.Rows = append(rt.Rows, row)
+		}
+	}
+	return rt.render(opts)
+}
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrender
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format is an output format for type Table.
+type Format string
+
+const (
+	// TextFormat renders a plain-text table with aligned columns, for display in a terminal.
+	TextFormat Format = "text"
+	// CSVFormat renders comma-separated values as per RFC 4180, with the column titles in the first record.
+	CSVFormat Format = "csv"
+	// MarkdownFormat renders a table in GitHub-flavored Markdown.
+	MarkdownFormat Format = "markdown"
+)
+
+// ParseFormat parses the string representation of a Format,
+// e.g. from a command-line flag.
+func ParseFormat(input string) (Format, error) {
+	switch f := Format(input); f {
+	case TextFormat, CSVFormat, MarkdownFormat:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format: %q (expected %q, %q or %q)", input, TextFormat, CSVFormat, MarkdownFormat)
+	}
+}
+
+// Column describes a single column of a Table.
+type Column struct {
+	Title string
+	// If true, cells in this column are aligned to the right in TextFormat and
+	// MarkdownFormat. This is used for all columns that contain values.
+	RightAligned bool
+}
+
+// Table is a rendered report. Each row has exactly one cell per column.
+// Cells that do not have a value (e.g. quota on a resource without quota) are empty strings.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// Write writes this table to the given writer in the given format.
+func (t Table) Write(w io.Writer, format Format) error {
+	switch format {
+	case TextFormat:
+		return t.writeText(w)
+	case CSVFormat:
+		return t.writeCSV(w)
+	case MarkdownFormat:
+		return t.writeMarkdown(w)
+	default:
+		return fmt.Errorf("unknown output format: %q", format)
+	}
+}
+
+func (t Table) titles() []string {
+	result := make([]string, len(t.Columns))
+	for idx, col := range t.Columns {
+		result[idx] = col.Title
+	}
+	return result
+}
+
+func (t Table) writeText(w io.Writer) error {
+	allRows := append([][]string{t.titles()}, t.Rows...)
+	widths := make([]int, len(t.Columns))
+	for _, row := range allRows {
+		for idx, cell := range row {
+			widths[idx] = max(widths[idx], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var sb strings.Builder
+	for _, row := range allRows {
+		var line strings.Builder
+		for idx, cell := range row {
+			if idx > 0 {
+				line.WriteString("  ")
+			}
+			padding := strings.Repeat(" ", widths[idx]-utf8.RuneCountInString(cell))
+			if t.Columns[idx].RightAligned {
+				line.WriteString(padding + cell)
+			} else {
+				line.WriteString(cell + padding)
+			}
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " "))
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (t Table) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(t.titles())
+	if err != nil {
+		return err
+	}
+	err = cw.WriteAll(t.Rows) // also flushes
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func (t Table) writeMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+		for _, cell := range cells {
+			sb.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(t.titles())
+	separators := make([]string, len(t.Columns))
+	for idx, col := range t.Columns {
+		if col.RightAligned {
+			separators[idx] = "---:"
+		} else {
+			separators[idx] = "---"
+		}
+	}
+	writeRow(separators)
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
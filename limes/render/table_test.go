@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesrender
+
+import (
+	"strings"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+)
+
+var testTable = Table{
+	Columns: []Column{{Title: "name"}, {Title: "comment"}, {Title: "value", RightAligned: true}},
+	Rows: [][]string{
+		{"foo", "a|b", "5 GiB"},
+		{"bärbel", "with, comma", "12"},
+		{"qux", "", ""},
+	},
+}
+
+func TestTableWrite(t *testing.T) {
+	expected := map[Format]string{
+		TextFormat: `
+name    comment      value
+foo     a|b          5 GiB
+bärbel  with, comma     12
+qux
+`,
+		CSVFormat: `
+name,comment,value
+foo,a|b,5 GiB
+bärbel,"with, comma",12
+qux,,
+`,
+		MarkdownFormat: `
+| name | comment | value |
+| --- | --- | ---: |
+| foo | a\|b | 5 GiB |
+| bärbel | with, comma | 12 |
+| qux |  |  |
+`,
+	}
+
+	for format, output := range expected {
+		var sb strings.Builder
+		err := testTable.Write(&sb, format)
+		assert.ErrEqual(t, err, nil)
+		assert.Equal(t, sb.String(), strings.TrimPrefix(output, "\n"))
+
+		parsed, err := ParseFormat(string(format))
+		assert.ErrEqual(t, err, nil)
+		assert.Equal(t, parsed, format)
+	}
+
+	_, err := ParseFormat("html")
+	assert.ErrEqual(t, err, `unknown output format: "html" (expected "text", "csv" or "markdown")`)
+	err = testTable.Write(&strings.Builder{}, Format("html"))
+	assert.ErrEqual(t, err, `unknown output format: "html"`)
+}
@@ -0,0 +1,314 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"encoding/json"
+
+	"github.com/sapcc/go-api-declarations/limes"
+)
+
+// AggregateDomainReport computes a domain report from the reports of all
+// projects within that domain, in the same way as Limes does for its domain
+// level API.
+//
+// Since domain quotas are not visible on the project level, DomainQuota is
+// never filled. Keys of commitment maps are normalized through
+// ParseCommitmentDuration, such that e.g. "1year" and "1 year" are counted
+// together. Keys that cannot be parsed are taken over verbatim.
+func AggregateDomainReport(domain limes.DomainInfo, projects []ProjectReport) DomainReport {
+	result := DomainReport{
+		DomainInfo: domain,
+		Services:   make(DomainServiceReports),
+	}
+	scrapeTimes := make(map[limes.ServiceType]*scrapeTimeRange)
+
+	for _, project := range projects {
+		for serviceType, projectService := range project.Services {
+			service := result.Services[serviceType]
+			if service == nil {
+				service = &DomainServiceReport{
+					ServiceInfo: projectService.ServiceInfo,
+					Resources:   make(DomainResourceReports),
+				}
+				result.Services[serviceType] = service
+				scrapeTimes[serviceType] = &scrapeTimeRange{}
+			}
+			scrapeTimes[serviceType].Add(projectService.ScrapedAt)
+
+			for resourceName, projectResource := range projectService.Resources {
+				resource := service.Resources[resourceName]
+				if resource == nil {
+					resource = &DomainResourceReport{
+						ResourceInfo:           projectResource.ResourceInfo,
+						QuotaDistributionModel: projectResource.QuotaDistributionModel,
+						CommitmentConfig:       projectResource.CommitmentConfig,
+					}
+					service.Resources[resourceName] = resource
+				}
+				resource.addProjectResourceReport(*projectResource)
+			}
+		}
+	}
+
+	for serviceType, service := range result.Services {
+		for _, resource := range service.Resources {
+			resource.cleanupBackendQuota()
+		}
+		service.MinScrapedAt = scrapeTimes[serviceType].Min
+		service.MaxScrapedAt = scrapeTimes[serviceType].Max
+	}
+	return result
+}
+
+func (r *DomainResourceReport) addProjectResourceReport(projectResource ProjectResourceReport) {
+	addToOptional(&r.ProjectsQuota, projectResource.Quota)
+	addPhysicalUsage(&r.PhysicalUsage, r.Usage, projectResource.PhysicalUsage, projectResource.Usage)
+	r.Usage += projectResource.Usage
+
+	// backend quota is summed up separately, see cleanupBackendQuota()
+	switch {
+	case projectResource.BackendQuota != nil && *projectResource.BackendQuota < 0:
+		r.InfiniteBackendQuota = new(true)
+	case projectResource.BackendQuota != nil:
+		backendQuota := uint64(*projectResource.BackendQuota)
+		addToOptional(&r.BackendQuota, &backendQuota)
+	default:
+		addToOptional(&r.BackendQuota, projectResource.Quota)
+	}
+
+	if len(projectResource.PerAZ) > 0 && r.PerAZ == nil {
+		r.PerAZ = make(DomainAZResourceReports)
+	}
+	for az, projectAZReport := range projectResource.PerAZ {
+		azReport := r.PerAZ[az]
+		if azReport == nil {
+			azReport = &DomainAZResourceReport{}
+			r.PerAZ[az] = azReport
+		}
+		addToOptional(&azReport.Quota, projectAZReport.Quota)
+		azReport.Usage += projectAZReport.Usage
+		addCommitments(&azReport.Committed, projectAZReport.Committed)
+		addCommitments(&azReport.PendingCommitments, projectAZReport.PendingCommitments)
+		addCommitments(&azReport.PlannedCommitments, projectAZReport.PlannedCommitments)
+
+		unused, uncommitted := splitUsageByCommitments(projectAZReport.Usage, projectAZReport.Committed)
+		azReport.UnusedCommitments += unused
+		azReport.UncommittedUsage += uncommitted
+	}
+}
+
+// BackendQuota is only reported if it differs from ProjectsQuota,
+// so this needs to be cleaned up after aggregation.
+func (r *DomainResourceReport) cleanupBackendQuota() {
+	if r.InfiniteBackendQuota != nil || r.BackendQuota == nil {
+		r.BackendQuota = nil
+		return
+	}
+	if r.ProjectsQuota != nil && *r.BackendQuota == *r.ProjectsQuota {
+		r.BackendQuota = nil
+	}
+}
+
+// AZCapacity contains capacity data for a single resource in a single
+// availability zone. It is used as input for func AggregateClusterReport.
+type AZCapacity struct {
+	Capacity    uint64
+	RawCapacity uint64 // only relevant if an overcommit factor is applied to the capacity
+	// Usage is what the backend reports as usage across all projects, if it does so.
+	Usage         *uint64
+	Subcapacities json.RawMessage
+}
+
+// AggregateClusterReport computes a cluster report from the reports of all
+// domains within that cluster and the capacity data for all resources, in
+// the same way as Limes does for its cluster level API.
+//
+// The capacity data is given as capacity[serviceType][resourceName][az].
+// Resources that only appear in the capacity data, but not in any domain
+// report, are not reported since their metadata is not known.
+//
+// The resulting report uses the PerAZ breakdown of the v2 API feature preview,
+// so CapacityPerAZ is never filled. Since domain reports do not break down
+// physical usage by AZ, the PhysicalUsage of each AZ is never filled either.
+func AggregateClusterReport(cluster limes.ClusterInfo, domains []DomainReport, capacity map[limes.ServiceType]map[ResourceName]map[limes.AvailabilityZone]AZCapacity) ClusterReport {
+	result := ClusterReport{
+		ClusterInfo: cluster,
+		Services:    make(ClusterServiceReports),
+	}
+	var clusterScrapeTimes scrapeTimeRange
+	scrapeTimes := make(map[limes.ServiceType]*scrapeTimeRange)
+
+	for _, domain := range domains {
+		for serviceType, domainService := range domain.Services {
+			service := result.Services[serviceType]
+			if service == nil {
+				service = &ClusterServiceReport{
+					ServiceInfo: domainService.ServiceInfo,
+					Resources:   make(ClusterResourceReports),
+				}
+				result.Services[serviceType] = service
+				scrapeTimes[serviceType] = &scrapeTimeRange{}
+			}
+			scrapeTimes[serviceType].Add(domainService.MinScrapedAt)
+			scrapeTimes[serviceType].Add(domainService.MaxScrapedAt)
+
+			for resourceName, domainResource := range domainService.Resources {
+				resource := service.Resources[resourceName]
+				if resource == nil {
+					resource = &ClusterResourceReport{
+						ResourceInfo:           domainResource.ResourceInfo,
+						QuotaDistributionModel: domainResource.QuotaDistributionModel,
+						CommitmentConfig:       domainResource.CommitmentConfig,
+					}
+					service.Resources[resourceName] = resource
+				}
+				resource.addDomainResourceReport(*domainResource)
+			}
+		}
+	}
+
+	for serviceType, service := range result.Services {
+		for resourceName, resource := range service.Resources {
+			resource.addCapacity(capacity[serviceType][resourceName])
+		}
+		service.MinScrapedAt = scrapeTimes[serviceType].Min
+		service.MaxScrapedAt = scrapeTimes[serviceType].Max
+		clusterScrapeTimes.Add(service.MinScrapedAt)
+		clusterScrapeTimes.Add(service.MaxScrapedAt)
+	}
+	result.MinScrapedAt = clusterScrapeTimes.Min
+	result.MaxScrapedAt = clusterScrapeTimes.Max
+	return result
+}
+
+func (r *ClusterResourceReport) addDomainResourceReport(domainResource DomainResourceReport) {
+	addToOptional(&r.DomainsQuota, domainResource.DomainQuota)
+	addPhysicalUsage(&r.PhysicalUsage, r.Usage, domainResource.PhysicalUsage, domainResource.Usage)
+	r.Usage += domainResource.Usage
+
+	if len(domainResource.PerAZ) > 0 && r.PerAZ == nil {
+		r.PerAZ = make(ClusterAZResourceReports)
+	}
+	for az, domainAZReport := range domainResource.PerAZ {
+		azReport := r.PerAZ[az]
+		if azReport == nil {
+			azReport = &ClusterAZResourceReport{}
+			r.PerAZ[az] = azReport
+		}
+		azReport.ProjectsUsage += domainAZReport.Usage
+		addCommitments(&azReport.Committed, domainAZReport.Committed)
+		addCommitments(&azReport.PendingCommitments, domainAZReport.PendingCommitments)
+		addCommitments(&azReport.PlannedCommitments, domainAZReport.PlannedCommitments)
+		azReport.UnusedCommitments += domainAZReport.UnusedCommitments
+		azReport.UncommittedUsage += domainAZReport.UncommittedUsage
+	}
+}
+
+func (r *ClusterResourceReport) addCapacity(capacityPerAZ map[limes.AvailabilityZone]AZCapacity) {
+	if len(capacityPerAZ) == 0 {
+		return
+	}
+	if r.PerAZ == nil {
+		r.PerAZ = make(ClusterAZResourceReports)
+	}
+
+	var totalCapacity, totalRawCapacity uint64
+	for az, azCapacity := range capacityPerAZ {
+		azReport := r.PerAZ[az]
+		if azReport == nil {
+			azReport = &ClusterAZResourceReport{}
+			r.PerAZ[az] = azReport
+		}
+		azReport.Capacity = azCapacity.Capacity
+		azReport.RawCapacity = azCapacity.RawCapacity
+		azReport.Usage = azCapacity.Usage
+		azReport.Subcapacities = azCapacity.Subcapacities
+		totalCapacity += azCapacity.Capacity
+		totalRawCapacity += azCapacity.RawCapacity
+	}
+
+	r.Capacity = &totalCapacity
+	if totalRawCapacity > 0 {
+		r.RawCapacity = &totalRawCapacity
+	}
+}
+
+// scrapeTimeRange collects the minimum and maximum of a set of scrape timestamps.
+type scrapeTimeRange struct {
+	Min *limes.UnixEncodedTime
+	Max *limes.UnixEncodedTime
+}
+
+// Add includes the given timestamp in the range, unless it is nil.
+func (r *scrapeTimeRange) Add(t *limes.UnixEncodedTime) {
+	if t == nil {
+		return
+	}
+	t = &limes.UnixEncodedTime{Time: t.Time} // avoid aliasing with the input reports
+	if r.Min == nil || t.Before(r.Min.Time) {
+		r.Min = t
+	}
+	if r.Max == nil || t.After(r.Max.Time) {
+		r.Max = t
+	}
+}
+
+// addToOptional adds `value` to `*sum`, unless `value` is nil.
+// If `*sum` is nil, it is initialized first.
+func addToOptional(sum **uint64, value *uint64) {
+	if value == nil {
+		return
+	}
+	if *sum == nil {
+		*sum = new(uint64(0))
+	}
+	**sum += *value
+}
+
+// addPhysicalUsage adds physical usage into `*sum`. If no physical usage was
+// reported so far, `usageSoFar` is taken as the physical usage of everything
+// that was aggregated before. If `physicalUsage` is nil, `usage` is added instead.
+func addPhysicalUsage(sum **uint64, usageSoFar uint64, physicalUsage *uint64, usage uint64) {
+	if *sum == nil {
+		if physicalUsage == nil {
+			return
+		}
+		*sum = new(usageSoFar)
+	}
+	if physicalUsage == nil {
+		**sum += usage
+	} else {
+		**sum += *physicalUsage
+	}
+}
+
+// addCommitments adds the commitment amounts from `values` into `*sum`,
+// while normalizing the commitment durations that are used as keys.
+func addCommitments(sum *map[string]uint64, values map[string]uint64) {
+	if len(values) == 0 {
+		return
+	}
+	if *sum == nil {
+		*sum = make(map[string]uint64, len(values))
+	}
+	for key, value := range values {
+		duration, err := ParseCommitmentDuration(key)
+		if err == nil {
+			key = duration.String()
+		}
+		(*sum)[key] += value
+	}
+}
+
+// splitUsageByCommitments computes how much of the committed amount is not
+// covered by usage, and how much of the usage is not covered by commitments.
+func splitUsageByCommitments(usage uint64, committed map[string]uint64) (unusedCommitments, uncommittedUsage uint64) {
+	var totalCommitted uint64
+	for _, amount := range committed {
+		totalCommitted += amount
+	}
+	coveredUsage := min(usage, totalCommitted)
+	return totalCommitted - coveredUsage, usage - coveredUsage
+}
@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"encoding/json"
+	"testing"
+
+	th "github.com/sapcc/go-api-declarations/internal/testhelper"
+	"github.com/sapcc/go-api-declarations/limes"
+)
+
+var aggregateTestProjects = []ProjectReport{
+	{
+		ProjectInfo: limes.ProjectInfo{UUID: "uuid-for-berlin", Name: "berlin"},
+		Services: ProjectServiceReports{
+			"shared": &ProjectServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+				ScrapedAt:   p2time(22),
+				Resources: ProjectResourceReports{
+					"capacity": &ProjectResourceReport{
+						ResourceInfo: ResourceInfo{Name: "capacity", Unit: limes.UnitBytes},
+						PerAZ: ProjectAZResourceReports{
+							"az-one": {Quota: new(uint64(6)), Usage: 2, Committed: map[string]uint64{"1 year": 3}},
+							"az-two": {Quota: new(uint64(4)), Usage: 3, PendingCommitments: map[string]uint64{"1 year": 5}},
+						},
+						Quota:         new(uint64(10)),
+						Usage:         5,
+						PhysicalUsage: new(uint64(1)),
+					},
+					"things": &ProjectResourceReport{
+						ResourceInfo: ResourceInfo{Name: "things"},
+						PerAZ:        ProjectAZResourceReports{limes.AvailabilityZoneAny: {Usage: 2}},
+						Quota:        new(uint64(10)),
+						Usage:        2,
+						BackendQuota: new(int64(12)),
+					},
+				},
+			},
+		},
+	},
+	{
+		ProjectInfo: limes.ProjectInfo{UUID: "uuid-for-dresden", Name: "dresden"},
+		Services: ProjectServiceReports{
+			"shared": &ProjectServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+				ScrapedAt:   p2time(11),
+				Resources: ProjectResourceReports{
+					"capacity": &ProjectResourceReport{
+						ResourceInfo: ResourceInfo{Name: "capacity", Unit: limes.UnitBytes},
+						PerAZ: ProjectAZResourceReports{
+							"az-one": {Quota: new(uint64(2)), Usage: 4, Committed: map[string]uint64{"1year": 2, "2 years": 1}},
+						},
+						Quota: new(uint64(2)),
+						Usage: 4,
+					},
+					"things": &ProjectResourceReport{
+						ResourceInfo: ResourceInfo{Name: "things"},
+						PerAZ:        ProjectAZResourceReports{limes.AvailabilityZoneAny: {Usage: 1}},
+						Quota:        new(uint64(5)),
+						Usage:        1,
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestAggregateDomainAndClusterReport(t *testing.T) {
+	domainReport := AggregateDomainReport(limes.DomainInfo{UUID: "uuid-for-germany", Name: "germany"}, aggregateTestProjects)
+	th.CheckJSONEquals(t, `{
+		"id": "uuid-for-germany",
+		"name": "germany",
+		"services": [{
+			"type": "shared",
+			"area": "shared",
+			"resources": [
+				{
+					"name": "capacity",
+					"unit": "B",
+					"per_az": {
+						"az-one": {"quota": 8, "usage": 6, "committed": {"1 year": 5, "2 years": 1}, "unused_commitments": 1, "uncommitted_usage": 1},
+						"az-two": {"quota": 4, "usage": 3, "pending_commitments": {"1 year": 5}, "uncommitted_usage": 3}
+					},
+					"projects_quota": 12,
+					"usage": 9,
+					"physical_usage": 5
+				},
+				{
+					"name": "things",
+					"per_az": {"any": {"usage": 3, "uncommitted_usage": 3}},
+					"projects_quota": 15,
+					"usage": 3,
+					"backend_quota": 17
+				}
+			],
+			"max_scraped_at": 22,
+			"min_scraped_at": 11
+		}]
+	}`, domainReport)
+
+	// the input reports were not modified by the normalization of commitment durations
+	th.CheckJSONEquals(t, `{"1year": 2, "2 years": 1}`, aggregateTestProjects[1].Services["shared"].Resources["capacity"].PerAZ["az-one"].Committed)
+
+	otherDomainReport := AggregateDomainReport(limes.DomainInfo{UUID: "uuid-for-france", Name: "france"}, []ProjectReport{{
+		ProjectInfo: limes.ProjectInfo{UUID: "uuid-for-paris", Name: "paris"},
+		Services: ProjectServiceReports{
+			"shared": &ProjectServiceReport{
+				ServiceInfo: limes.ServiceInfo{Type: "shared", Area: "shared"},
+				ScrapedAt:   p2time(33),
+				Resources: ProjectResourceReports{
+					"things": &ProjectResourceReport{
+						ResourceInfo: ResourceInfo{Name: "things"},
+						PerAZ:        ProjectAZResourceReports{limes.AvailabilityZoneAny: {Usage: 4, Committed: map[string]uint64{"1 year": 6}}},
+						Quota:        new(uint64(6)),
+						Usage:        4,
+						BackendQuota: new(int64(-1)),
+					},
+				},
+			},
+		},
+	}})
+	otherDomainReport.Services["shared"].Resources["things"].DomainQuota = new(uint64(20))
+	th.CheckJSONEquals(t, `{
+		"name": "things",
+		"per_az": {"any": {"usage": 4, "committed": {"1 year": 6}, "unused_commitments": 2}},
+		"quota": 20,
+		"projects_quota": 6,
+		"usage": 4,
+		"infinite_backend_quota": true
+	}`, otherDomainReport.Services["shared"].Resources["things"])
+
+	capacity := map[limes.ServiceType]map[ResourceName]map[limes.AvailabilityZone]AZCapacity{
+		"shared": {
+			"capacity": {
+				"az-one": {Capacity: 100, RawCapacity: 50, Subcapacities: json.RawMessage(`[{"name":"pool1"}]`)},
+				"az-two": {Capacity: 60, RawCapacity: 30, Usage: new(uint64(4))},
+			},
+			"unknown": {
+				"az-one": {Capacity: 10},
+			},
+		},
+	}
+	clusterReport := AggregateClusterReport(limes.ClusterInfo{ID: "current"}, []DomainReport{domainReport, otherDomainReport}, capacity)
+	th.CheckJSONEquals(t, `{
+		"id": "current",
+		"services": [{
+			"type": "shared",
+			"area": "shared",
+			"resources": [
+				{
+					"name": "capacity",
+					"unit": "B",
+					"capacity": 160,
+					"raw_capacity": 80,
+					"per_az": {
+						"az-one": {"capacity": 100, "raw_capacity": 50, "projects_usage": 6, "committed": {"1 year": 5, "2 years": 1}, "unused_commitments": 1, "uncommitted_usage": 1, "subcapacities": [{"name":"pool1"}]},
+						"az-two": {"capacity": 60, "raw_capacity": 30, "usage": 4, "projects_usage": 3, "pending_commitments": {"1 year": 5}, "uncommitted_usage": 3}
+					},
+					"usage": 9,
+					"physical_usage": 5
+				},
+				{
+					"name": "things",
+					"per_az": {"any": {"capacity": 0, "projects_usage": 7, "committed": {"1 year": 6}, "unused_commitments": 2, "uncommitted_usage": 3}},
+					"domains_quota": 20,
+					"usage": 7
+				}
+			],
+			"max_scraped_at": 33,
+			"min_scraped_at": 11
+		}],
+		"max_scraped_at": 33,
+		"min_scraped_at": 11
+	}`, clusterReport)
+}
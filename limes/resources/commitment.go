@@ -4,10 +4,14 @@
 package limesresources
 
 import (
+	"cmp"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -100,6 +104,9 @@ const (
 // (which are commonly used in automated tests for convenience and clarity),
 // but also allows large durations with calendar-compatible calculations
 // (e.g. "1y" is actually one year and not just 365 days).
+//
+// When serializing and deserializing, the syntax of func ParseCommitmentDuration is used.
+// To exchange durations in the ISO 8601 syntax, use type ISO8601CommitmentDuration.
 type CommitmentDuration struct {
 	// NOTE: this does not use uint etc. because time.Time.AddDate() wants int
 	Years  int
@@ -185,6 +192,140 @@ func (d CommitmentDuration) AddTo(t time.Time) time.Time {
 	return t.AddDate(d.Years, d.Months, d.Days).Add(d.Short)
 }
 
+var cdISO8601Rx = regexp.MustCompile(`^P(?:([0-9]+)Y)?(?:([0-9]+)M)?(?:([0-9]+)W)?(?:([0-9]+)D)?(?:T(?:([0-9]+)H)?(?:([0-9]+)M)?(?:([0-9]+)S)?)?$`)
+
+// ParseISO8601CommitmentDuration parses a CommitmentDuration from the duration
+// syntax of ISO 8601, e.g. "P1Y2M" or "P3DT4H". Weeks are converted into days.
+// Fractional values (e.g. "PT1.5S") are not supported.
+func ParseISO8601CommitmentDuration(input string) (CommitmentDuration, error) {
+	match := cdISO8601Rx.FindStringSubmatch(input)
+	if match == nil || input == "P" || strings.HasSuffix(input, "T") {
+		return CommitmentDuration{}, fmt.Errorf("could not parse CommitmentDuration %q: malformed ISO 8601 duration", input)
+	}
+	var numbers [7]int
+	for idx, field := range match[1:] {
+		if field == "" {
+			continue
+		}
+		var err error
+		numbers[idx], err = strconv.Atoi(field)
+		if err != nil {
+			return CommitmentDuration{}, fmt.Errorf("could not parse CommitmentDuration %q: %w", input, err)
+		}
+	}
+
+	// compute days and the short part with overflow checks (all numbers are non-negative because of the regex)
+	days, ok1 := mulAddWithoutOverflow(int64(numbers[3]), int64(numbers[2]), 7)
+	short, ok2 := mulAddWithoutOverflow(0, int64(numbers[4]), int64(time.Hour))
+	short, ok3 := mulAddWithoutOverflow(short, int64(numbers[5]), int64(time.Minute))
+	short, ok4 := mulAddWithoutOverflow(short, int64(numbers[6]), int64(time.Second))
+	if !ok1 || !ok2 || !ok3 || !ok4 || days > math.MaxInt {
+		return CommitmentDuration{}, fmt.Errorf("could not parse CommitmentDuration %q: value is too large", input)
+	}
+
+	result := CommitmentDuration{
+		Years:  numbers[0],
+		Months: numbers[1],
+		Days:   int(days),
+		Short:  time.Duration(short),
+	}
+	if result.Years == 0 && result.Months == 0 && result.Days == 0 && result.Short == 0 {
+		return CommitmentDuration{}, fmt.Errorf("could not parse CommitmentDuration %q: empty duration", input)
+	}
+	return result, nil
+}
+
+// parseCommitmentDurationAnySyntax accepts both the syntax of ParseCommitmentDuration
+// and that of ParseISO8601CommitmentDuration. Since the former never starts with
+// the letter "P", there is no ambiguity.
+func parseCommitmentDurationAnySyntax(input string) (CommitmentDuration, error) {
+	if strings.HasPrefix(input, "P") {
+		return ParseISO8601CommitmentDuration(input)
+	}
+	return ParseCommitmentDuration(input)
+}
+
+// ISO8601 returns the representation of this duration in the duration syntax of ISO 8601.
+// This is the inverse of func ParseISO8601CommitmentDuration.
+//
+//	d, _ := ParseCommitmentDuration("1 year, 2 months, 3 days, 4 hours")
+//	d.ISO8601() // -> "P1Y2M3DT4H"
+//
+// An error is returned for durations that cannot be parsed back by
+// ParseISO8601CommitmentDuration, i.e. if the duration is zero, if any part
+// of the duration is negative, or if the Short part is not a whole number of seconds.
+func (d CommitmentDuration) ISO8601() (string, error) {
+	if d.Years < 0 || d.Months < 0 || d.Days < 0 || d.Short < 0 {
+		return "", fmt.Errorf("cannot represent CommitmentDuration %#v in ISO 8601: negative values are not allowed", d)
+	}
+	if d.Short%time.Second != 0 {
+		return "", fmt.Errorf("cannot represent CommitmentDuration in ISO 8601: Short = %s is not a whole number of seconds", d.Short)
+	}
+	if d == (CommitmentDuration{}) {
+		return "", errors.New("cannot represent CommitmentDuration in ISO 8601: empty duration")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("P")
+	format := func(amount int, designator string) {
+		if amount != 0 {
+			sb.WriteString(strconv.Itoa(amount) + designator)
+		}
+	}
+	format(d.Years, "Y")
+	format(d.Months, "M")
+	format(d.Days, "D")
+
+	hours := int(d.Short / time.Hour)
+	minutes := int(d.Short % time.Hour / time.Minute)
+	seconds := int(d.Short % time.Minute / time.Second)
+	if hours != 0 || minutes != 0 || seconds != 0 {
+		sb.WriteString("T")
+		format(hours, "H")
+		format(minutes, "M")
+		format(seconds, "S")
+	}
+
+	return sb.String(), nil
+}
+
+// mulAddWithoutOverflow returns acc + amount * factor, or false if the result
+// does not fit into int64. All arguments must be non-negative, and factor must be positive.
+func mulAddWithoutOverflow(acc, amount, factor int64) (int64, bool) {
+	if amount > (math.MaxInt64-acc)/factor {
+		return 0, false
+	}
+	return acc + amount*factor, true
+}
+
+// Normalize returns an equivalent duration where whole multiples of 12 months
+// are counted as years instead, e.g. "18 months" becomes "1 year, 6 months".
+// Days are not converted into months and hours are not converted into days,
+// since the length of those units is not fixed.
+func (d CommitmentDuration) Normalize() CommitmentDuration {
+	d.Years += d.Months / 12
+	d.Months %= 12
+	return d
+}
+
+// Compare returns -1, 0 or +1 depending on whether this duration is shorter,
+// equally long or longer than the other duration, when both are applied to
+// the given reference time. The reference time is required because months
+// and years do not have a fixed length.
+func (d CommitmentDuration) Compare(other CommitmentDuration, reference time.Time) int {
+	return d.AddTo(reference).Compare(other.AddTo(reference))
+}
+
+// SortCommitmentDurations sorts the given durations by their length relative
+// to the given reference time, as per func CommitmentDuration.Compare.
+// Durations of equal length are ordered by their string representation,
+// so the result does not depend on the original order of the list.
+func SortCommitmentDurations(durations []CommitmentDuration, reference time.Time) {
+	slices.SortFunc(durations, func(lhs, rhs CommitmentDuration) int {
+		return cmp.Or(lhs.Compare(rhs, reference), strings.Compare(lhs.String(), rhs.String()))
+	})
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (d CommitmentDuration) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.String())
@@ -250,3 +391,105 @@ func (d *CommitmentDuration) Scan(src any) (err error) {
 func (d CommitmentDuration) Value() (driver.Value, error) {
 	return driver.Value(d.String()), nil
 }
+
+// ISO8601CommitmentDuration is a variant of CommitmentDuration that is
+// serialized in the duration syntax of ISO 8601 (e.g. "P1Y2M") for JSON, YAML,
+// text and SQL. Unlike CommitmentDuration, it accepts both syntaxes when deserializing.
+//
+// This type can be used in place of CommitmentDuration when exchanging data
+// with systems that only understand ISO 8601 durations. Values can be
+// converted between both types with a plain type conversion.
+type ISO8601CommitmentDuration CommitmentDuration
+
+// String returns the ISO 8601 representation of this duration.
+// If the duration cannot be represented in ISO 8601 (see func
+// CommitmentDuration.ISO8601), the regular syntax is used instead.
+func (d ISO8601CommitmentDuration) String() string {
+	result, err := CommitmentDuration(d).ISO8601()
+	if err != nil {
+		return CommitmentDuration(d).String()
+	}
+	return result
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d ISO8601CommitmentDuration) MarshalJSON() ([]byte, error) {
+	result, err := CommitmentDuration(d).ISO8601()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *ISO8601CommitmentDuration) UnmarshalJSON(input []byte) error {
+	var s string
+	err := json.Unmarshal(input, &s)
+	if err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (d ISO8601CommitmentDuration) MarshalYAML() (any, error) {
+	return CommitmentDuration(d).ISO8601()
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (d *ISO8601CommitmentDuration) UnmarshalYAML(unmarshal func(any) error) error {
+	var input string
+	err := unmarshal(&input)
+	if err != nil {
+		return err
+	}
+	return d.parse(input)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d ISO8601CommitmentDuration) MarshalText() (text []byte, err error) {
+	result, err := CommitmentDuration(d).ISO8601()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(result), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *ISO8601CommitmentDuration) UnmarshalText(text []byte) (err error) {
+	return d.parse(string(text))
+}
+
+// Scan implements the sql.Scanner interface.
+func (d *ISO8601CommitmentDuration) Scan(src any) (err error) {
+	var srcString string
+	switch src := src.(type) {
+	case string:
+		srcString = src
+	case []byte:
+		srcString = string(src)
+	case nil:
+		srcString = ""
+	default:
+		return fmt.Errorf("cannot scan value of type %T into type limesresources.ISO8601CommitmentDuration", src)
+	}
+	return d.parse(srcString)
+}
+
+func (d *ISO8601CommitmentDuration) parse(input string) error {
+	result, err := parseCommitmentDurationAnySyntax(input)
+	if err != nil {
+		return err
+	}
+	*d = ISO8601CommitmentDuration(result)
+	return nil
+}
+
+// Value implements the sql/driver.Valuer interface.
+func (d ISO8601CommitmentDuration) Value() (driver.Value, error) {
+	result, err := CommitmentDuration(d).ISO8601()
+	if err != nil {
+		return nil, err
+	}
+	return driver.Value(result), nil
+}
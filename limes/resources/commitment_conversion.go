@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/internal/errorset"
+	"github.com/sapcc/go-api-declarations/limes"
+)
+
+// Apply computes how much of the given amount of the source resource can be
+// converted according to this rule. Since conversions only happen in whole
+// multiples of FromAmount, only part of the amount may be convertible:
+//
+//	rule := CommitmentConversionRule{FromAmount: 2, ToAmount: 3}
+//	rule.Apply(7) // -> sourceAmount = 6, targetAmount = 9
+//
+// The remainder (1 in this example) stays with the source resource.
+// An error is returned if the rule is malformed, or if the target amount does not fit into uint64.
+func (r CommitmentConversionRule) Apply(amount uint64) (sourceAmount, targetAmount uint64, err error) {
+	if r.FromAmount == 0 || r.ToAmount == 0 {
+		return 0, 0, fmt.Errorf("invalid conversion rate %d:%d", r.FromAmount, r.ToAmount)
+	}
+	multiple := amount / r.FromAmount
+	hi, lo := bits.Mul64(multiple, r.ToAmount)
+	if hi != 0 {
+		return 0, 0, fmt.Errorf("overflow while converting %d x %d:%d", multiple*r.FromAmount, r.FromAmount, r.ToAmount)
+	}
+	return multiple * r.FromAmount, lo, nil
+}
+
+// CommitmentConversion describes one possible way of converting a commitment
+// into a different resource. It is returned by func ConvertCommitment.
+type CommitmentConversion struct {
+	Rule CommitmentConversionRule
+	// The part of the source commitment's amount that is converted.
+	// This is always an integer multiple of Rule.FromAmount.
+	SourceAmount uint64
+	// The part of the source commitment's amount that cannot be converted
+	// and stays with the source resource.
+	RemainingAmount uint64
+	// The commitment that will be created in the target resource.
+	Request CommitmentRequest
+}
+
+// ConvertCommitment computes all possible conversions of the given commitment
+// according to the given conversion rules, in the same order as the rules.
+//
+// The converted commitment keeps the duration of the source commitment.
+// Therefore, rules are skipped if the target resource does not accept
+// commitments with that duration, according to the respective entry in
+// `targetConfigs`. Durations are compared after normalization, so e.g. a
+// commitment for "12 months" can be converted into a resource that accepts
+// "1 year". The converted commitment uses the duration as spelled in the
+// target resource's configuration. Rules are also skipped if the commitment's amount is less
+// than the rule's FromAmount.
+//
+// If the source commitment is not confirmed yet, the converted commitment
+// keeps its ConfirmBy, but moved forward to `now` or to the target resource's
+// MinConfirmBy if it would otherwise be rejected by func ValidateCommitmentRequest.
+//
+// An error is returned if any of the rules is malformed or would overflow.
+func ConvertCommitment(c Commitment, rules []CommitmentConversionRule, targetConfigs map[limes.ServiceType]map[ResourceName]CommitmentConfiguration, now time.Time) ([]CommitmentConversion, error) {
+	var (
+		result []CommitmentConversion
+		errs   errorset.ErrorSet
+	)
+	for _, rule := range rules {
+		sourceAmount, targetAmount, err := rule.Apply(c.Amount)
+		if err != nil {
+			errs.Addf("cannot apply conversion rule for %s/%s: %w", rule.TargetService, rule.TargetResource, err)
+			continue
+		}
+		if sourceAmount == 0 {
+			continue
+		}
+		config, exists := targetConfigs[rule.TargetService][rule.TargetResource]
+		if !exists {
+			continue
+		}
+		durationIndex := slices.IndexFunc(config.Durations, func(d CommitmentDuration) bool {
+			return d.Normalize() == c.Duration.Normalize()
+		})
+		if durationIndex == -1 {
+			continue
+		}
+
+		request := CommitmentRequest{
+			ServiceType:      rule.TargetService,
+			ResourceName:     rule.TargetResource,
+			AvailabilityZone: c.AvailabilityZone,
+			Amount:           targetAmount,
+			Duration:         config.Durations[durationIndex],
+		}
+		if c.ConfirmedAt == nil {
+			// if the source commitment is not confirmed yet, the converted commitment shall be confirmed under the same conditions
+			// (as far as the target resource allows)
+			if c.ConfirmBy != nil {
+				confirmBy := c.ConfirmBy.Time
+				if confirmBy.Before(now) {
+					confirmBy = now
+				}
+				if config.MinConfirmBy != nil && confirmBy.Before(config.MinConfirmBy.Time) {
+					confirmBy = config.MinConfirmBy.Time
+				}
+				request.ConfirmBy = &limes.UnixEncodedTime{Time: confirmBy}
+			}
+			request.NotifyOnConfirm = c.NotifyOnConfirm
+		}
+		result = append(result, CommitmentConversion{
+			Rule:            rule,
+			SourceAmount:    sourceAmount,
+			RemainingAmount: c.Amount - sourceAmount,
+			Request:         request,
+		})
+	}
+	if !errs.IsEmpty() {
+		return nil, errors.New(errs.Join(", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"math"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/go-api-declarations/limes"
+)
+
+func TestCommitmentConversionRuleApply(t *testing.T) {
+	rule := CommitmentConversionRule{FromAmount: 2, ToAmount: 3}
+	sourceAmount, targetAmount, err := rule.Apply(7)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sourceAmount, 6)
+	assert.Equal(t, targetAmount, 9)
+
+	sourceAmount, targetAmount, err = rule.Apply(1)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sourceAmount, 0)
+	assert.Equal(t, targetAmount, 0)
+
+	// large amounts work as long as the result fits into uint64
+	rule = CommitmentConversionRule{FromAmount: 3, ToAmount: 2}
+	sourceAmount, targetAmount, err = rule.Apply(math.MaxUint64)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sourceAmount, math.MaxUint64)
+	assert.Equal(t, targetAmount, math.MaxUint64/3*2)
+
+	rule = CommitmentConversionRule{FromAmount: 2, ToAmount: 3}
+	_, _, err = rule.Apply(math.MaxUint64)
+	assert.ErrEqual(t, err, "overflow while converting 18446744073709551614 x 2:3")
+
+	rule = CommitmentConversionRule{FromAmount: 0, ToAmount: 3}
+	_, _, err = rule.Apply(42)
+	assert.ErrEqual(t, err, "invalid conversion rate 0:3")
+}
+
+func TestConvertCommitment(t *testing.T) {
+	oneYear := mustParseDuration(t, "1 year")
+	threeYears := mustParseDuration(t, "3 years")
+	commitment := Commitment{
+		ServiceType:      "compute",
+		ResourceName:     "instances_small",
+		AvailabilityZone: "az-one",
+		Amount:           5,
+		Duration:         oneYear,
+		ConfirmBy:        dayPtr(1),
+		NotifyOnConfirm:  true,
+	}
+	rules := []CommitmentConversionRule{
+		{FromAmount: 2, ToAmount: 1, TargetService: "compute", TargetResource: "instances_medium"},
+		{FromAmount: 4, ToAmount: 1, TargetService: "compute", TargetResource: "instances_large"},
+		{FromAmount: 8, ToAmount: 1, TargetService: "compute", TargetResource: "instances_xlarge"},
+		{FromAmount: 1, ToAmount: 2, TargetService: "compute", TargetResource: "instances_tiny"},
+	}
+	targetConfigs := map[limes.ServiceType]map[ResourceName]CommitmentConfiguration{
+		"compute": {
+			"instances_medium": {Durations: []CommitmentDuration{oneYear, threeYears}},
+			"instances_large":  {Durations: []CommitmentDuration{oneYear}},
+			"instances_xlarge": {Durations: []CommitmentDuration{oneYear}},
+			"instances_tiny":   {Durations: []CommitmentDuration{threeYears}},
+		},
+	}
+
+	// instances_xlarge is skipped because the amount is too small,
+	// instances_tiny is skipped because the duration is not allowed
+	conversions, err := ConvertCommitment(commitment, rules, targetConfigs, day(1))
+	assert.ErrEqual(t, err, nil)
+	assert.DeepEqual(t, "conversions", conversions, []CommitmentConversion{
+		{
+			Rule:            rules[0],
+			SourceAmount:    4,
+			RemainingAmount: 1,
+			Request: CommitmentRequest{
+				ServiceType:      "compute",
+				ResourceName:     "instances_medium",
+				AvailabilityZone: "az-one",
+				Amount:           2,
+				Duration:         oneYear,
+				ConfirmBy:        dayPtr(1),
+				NotifyOnConfirm:  true,
+			},
+		},
+		{
+			Rule:            rules[1],
+			SourceAmount:    4,
+			RemainingAmount: 1,
+			Request: CommitmentRequest{
+				ServiceType:      "compute",
+				ResourceName:     "instances_large",
+				AvailabilityZone: "az-one",
+				Amount:           1,
+				Duration:         oneYear,
+				ConfirmBy:        dayPtr(1),
+				NotifyOnConfirm:  true,
+			},
+		},
+	})
+
+	// if ConfirmBy is in the past or before the target's MinConfirmBy, it is moved forward to stay valid
+	now := day(10)
+	pastConfigs := map[limes.ServiceType]map[ResourceName]CommitmentConfiguration{
+		"compute": {
+			"instances_medium": {Durations: []CommitmentDuration{oneYear}},
+			"instances_large":  {Durations: []CommitmentDuration{oneYear}, MinConfirmBy: dayPtr(15)},
+		},
+	}
+	conversions, err = ConvertCommitment(commitment, rules[:2], pastConfigs, now)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, len(conversions), 2)
+	assert.DeepEqual(t, "ConfirmBy", conversions[0].Request.ConfirmBy, dayPtr(10))
+	assert.DeepEqual(t, "ConfirmBy", conversions[1].Request.ConfirmBy, dayPtr(15))
+	for idx, conversion := range conversions {
+		cfg := pastConfigs["compute"][rules[idx].TargetResource]
+		assert.ErrEqual(t, ValidateCommitmentRequest(conversion.Request, cfg, now), nil)
+	}
+
+	// durations are compared after normalization, and the target's spelling of the duration is used
+	twelveMonths := commitment
+	twelveMonths.Duration = mustParseDuration(t, "12 months")
+	conversions, err = ConvertCommitment(twelveMonths, rules[:2], targetConfigs, day(1))
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, len(conversions), 2)
+	assert.Equal(t, conversions[0].Request.Duration, oneYear)
+	assert.ErrEqual(t, ValidateCommitmentRequest(conversions[0].Request, targetConfigs["compute"]["instances_medium"], day(1)), nil)
+
+	// confirmed commitments convert into commitments without ConfirmBy
+	commitment.ConfirmedAt = dayPtr(1)
+	conversions, err = ConvertCommitment(commitment, rules[:1], targetConfigs, day(1))
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, len(conversions), 1)
+	assert.Equal(t, conversions[0].Request.ConfirmBy == nil, true)
+	assert.Equal(t, conversions[0].Request.NotifyOnConfirm, false)
+
+	// resources without commitment config do not accept conversions
+	conversions, err = ConvertCommitment(commitment, rules[:1], nil, day(1))
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, len(conversions), 0)
+
+	_, err = ConvertCommitment(commitment, []CommitmentConversionRule{
+		{FromAmount: 1, ToAmount: 0, TargetService: "compute", TargetResource: "instances_foo"},
+	}, targetConfigs, day(1))
+	assert.ErrEqual(t, err, "cannot apply conversion rule for compute/instances_foo: invalid conversion rate 1:0")
+}
@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/limes"
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+// CommitmentPlan is the result of func PlanCommitments.
+type CommitmentPlan struct {
+	// All commitments that expire within the planning window, sorted by ExpiresAt.
+	Expiring []Commitment
+	// Renewals for those commitments in Expiring that can be renewed and have not been renewed yet.
+	// Sorted in the same order as Expiring.
+	Renewals []CommitmentRenewal
+}
+
+// CommitmentRenewal describes the commitment that would be created when renewing an existing commitment.
+// The renewal commitment takes effect exactly when the existing commitment expires,
+// and runs for the same duration.
+type CommitmentRenewal struct {
+	Commitment Commitment
+	StartsAt   time.Time
+	ExpiresAt  time.Time
+}
+
+// PlanCommitments looks at the given commitments from the perspective of the
+// given point in time, and finds all commitments that expire before `now + window`.
+// Commitments that already expired or were superseded are ignored.
+//
+// Out of the expiring commitments, those that are confirmed (or guaranteed to be
+// confirmed) and have not been renewed yet are additionally reported as renewal candidates.
+func PlanCommitments(commitments []Commitment, now time.Time, window time.Duration) CommitmentPlan {
+	var result CommitmentPlan
+	until := now.Add(window)
+	for _, c := range commitments {
+		if !c.isActiveOrUpcoming() {
+			continue
+		}
+		if c.ExpiresAt.Before(now) || !c.ExpiresAt.Before(until) {
+			continue
+		}
+		result.Expiring = append(result.Expiring, c)
+	}
+	slices.SortStableFunc(result.Expiring, func(lhs, rhs Commitment) int {
+		return cmp.Or(lhs.ExpiresAt.Compare(rhs.ExpiresAt.Time), cmp.Compare(lhs.ID, rhs.ID))
+	})
+
+	for _, c := range result.Expiring {
+		if c.WasRenewed || !c.isConfirmedOrGuaranteed() {
+			continue
+		}
+		result.Renewals = append(result.Renewals, CommitmentRenewal{
+			Commitment: c,
+			StartsAt:   c.ExpiresAt.Time,
+			ExpiresAt:  c.Duration.AddTo(c.ExpiresAt.Time),
+		})
+	}
+	return result
+}
+
+func (c Commitment) isActiveOrUpcoming() bool {
+	return c.Status != liquid.CommitmentStatusExpired && c.Status != liquid.CommitmentStatusSuperseded
+}
+
+// If c.Status is not filled (as in responses from older Limes versions),
+// the status is guessed from the other fields as far as possible.
+func (c Commitment) isConfirmedOrGuaranteed() bool {
+	switch c.Status {
+	case liquid.CommitmentStatusConfirmed, liquid.CommitmentStatusGuaranteed:
+		return true
+	case "":
+		return c.ConfirmedAt != nil
+	default:
+		return false
+	}
+}
+
+// CommitmentScope identifies the resource and AZ that a commitment belongs to.
+type CommitmentScope struct {
+	ServiceType      limes.ServiceType
+	ResourceName     ResourceName
+	AvailabilityZone limes.AvailabilityZone
+}
+
+// CommittedAmountSample is a point in a time series generated by func CommittedAmountTimeSeries.
+// The committed amount is valid from this point in time until the next sample in the series.
+type CommittedAmountSample struct {
+	At     time.Time
+	Amount uint64
+}
+
+// CommittedAmountTimeSeries computes how the total committed amount of each
+// resource and AZ changes over time. Each time series is a step function that
+// starts at the earliest point where a commitment takes effect and ends with a
+// sample of amount zero when the last commitment expires.
+//
+// Confirmed commitments are counted from their ConfirmedAt timestamp onwards.
+// Unconfirmed commitments are counted from their ConfirmBy date onwards, such
+// that the time series can be used for forecasting. Unconfirmed commitments
+// without ConfirmBy date and superseded commitments are ignored, since it is
+// not known when (if ever) they are in effect.
+func CommittedAmountTimeSeries(commitments []Commitment) map[CommitmentScope][]CommittedAmountSample {
+	type change struct {
+		At      time.Time
+		Added   uint64
+		Removed uint64
+	}
+	changes := make(map[CommitmentScope][]change)
+	for _, c := range commitments {
+		if c.Status == liquid.CommitmentStatusSuperseded {
+			continue
+		}
+		var startsAt time.Time
+		switch {
+		case c.ConfirmedAt != nil:
+			startsAt = c.ConfirmedAt.Time
+		case c.ConfirmBy != nil:
+			startsAt = c.ConfirmBy.Time
+		default:
+			continue
+		}
+		if !startsAt.Before(c.ExpiresAt.Time) {
+			continue
+		}
+
+		scope := CommitmentScope{c.ServiceType, c.ResourceName, c.AvailabilityZone}
+		changes[scope] = append(changes[scope],
+			change{At: startsAt, Added: c.Amount},
+			change{At: c.ExpiresAt.Time, Removed: c.Amount},
+		)
+	}
+
+	result := make(map[CommitmentScope][]CommittedAmountSample, len(changes))
+	for scope, scopeChanges := range changes {
+		slices.SortStableFunc(scopeChanges, func(lhs, rhs change) int {
+			return lhs.At.Compare(rhs.At)
+		})
+
+		var (
+			samples []CommittedAmountSample
+			amount  uint64
+		)
+		for idx, ch := range scopeChanges {
+			// since every commitment starts strictly before it expires, this cannot underflow
+			amount = amount + ch.Added - ch.Removed
+			// changes at the same point in time are merged into a single sample
+			if idx+1 < len(scopeChanges) && scopeChanges[idx+1].At.Equal(ch.At) {
+				continue
+			}
+			// do not report samples that do not change the amount
+			if len(samples) > 0 && samples[len(samples)-1].Amount == amount {
+				continue
+			}
+			samples = append(samples, CommittedAmountSample{At: ch.At, Amount: amount})
+		}
+		result[scope] = samples
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"testing"
+	"time"
+
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/go-api-declarations/limes"
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+func mustParseDuration(t *testing.T, input string) CommitmentDuration {
+	t.Helper()
+	d, err := ParseCommitmentDuration(input)
+	assert.ErrEqual(t, err, nil)
+	return d
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, time.January, d, 0, 0, 0, 0, time.UTC)
+}
+
+func dayPtr(d int) *limes.UnixEncodedTime {
+	return &limes.UnixEncodedTime{Time: day(d)}
+}
+
+func TestPlanCommitments(t *testing.T) {
+	oneYear := mustParseDuration(t, "1 year")
+	tenDays := mustParseDuration(t, "10 days")
+	commitments := []Commitment{
+		// expires within the window, can be renewed
+		{ID: 1, Duration: oneYear, ConfirmedAt: dayPtr(1), ExpiresAt: limes.UnixEncodedTime{Time: day(20)}, Status: liquid.CommitmentStatusConfirmed},
+		// expires within the window, but was already renewed
+		{ID: 2, Duration: tenDays, ConfirmedAt: dayPtr(1), ExpiresAt: limes.UnixEncodedTime{Time: day(11)}, Status: liquid.CommitmentStatusConfirmed, WasRenewed: true},
+		// expires within the window, status is derived from ConfirmedAt
+		{ID: 3, Duration: tenDays, ConfirmedAt: dayPtr(5), ExpiresAt: limes.UnixEncodedTime{Time: day(15)}},
+		// expires within the window, but is not confirmed and thus cannot be renewed
+		{ID: 4, Duration: tenDays, ConfirmBy: dayPtr(2), ExpiresAt: limes.UnixEncodedTime{Time: day(12)}, Status: liquid.CommitmentStatusPending},
+		// expires after the window
+		{ID: 5, Duration: oneYear, ConfirmedAt: dayPtr(1), ExpiresAt: limes.UnixEncodedTime{Time: day(30)}, Status: liquid.CommitmentStatusConfirmed},
+		// already expired or superseded
+		{ID: 6, Duration: tenDays, ConfirmedAt: dayPtr(1), ExpiresAt: limes.UnixEncodedTime{Time: day(9)}, Status: liquid.CommitmentStatusConfirmed},
+		{ID: 7, Duration: tenDays, ConfirmedAt: dayPtr(5), ExpiresAt: limes.UnixEncodedTime{Time: day(15)}, Status: liquid.CommitmentStatusSuperseded},
+	}
+
+	plan := PlanCommitments(commitments, day(10), 15*24*time.Hour)
+	var expiringIDs []int64
+	for _, c := range plan.Expiring {
+		expiringIDs = append(expiringIDs, c.ID)
+	}
+	assert.DeepEqual(t, "expiring IDs", expiringIDs, []int64{2, 4, 3, 1})
+
+	assert.Equal(t, len(plan.Renewals), 2)
+	assert.Equal(t, plan.Renewals[0].Commitment.ID, 3)
+	assert.Equal(t, plan.Renewals[0].StartsAt, day(15))
+	assert.Equal(t, plan.Renewals[0].ExpiresAt, day(25))
+	assert.Equal(t, plan.Renewals[1].Commitment.ID, 1)
+	assert.Equal(t, plan.Renewals[1].StartsAt, day(20))
+	assert.Equal(t, plan.Renewals[1].ExpiresAt, time.Date(2027, time.January, 20, 0, 0, 0, 0, time.UTC))
+}
+
+func TestCommittedAmountTimeSeries(t *testing.T) {
+	scopeOne := CommitmentScope{"shared", "capacity", "az-one"}
+	scopeTwo := CommitmentScope{"shared", "capacity", "az-two"}
+	commitments := []Commitment{
+		{ServiceType: "shared", ResourceName: "capacity", AvailabilityZone: "az-one", Amount: 10, ConfirmedAt: dayPtr(1), ExpiresAt: limes.UnixEncodedTime{Time: day(11)}},
+		{ServiceType: "shared", ResourceName: "capacity", AvailabilityZone: "az-one", Amount: 5, ConfirmedAt: dayPtr(5), ExpiresAt: limes.UnixEncodedTime{Time: day(20)}},
+		// renewal of the first commitment: starts exactly when the first one expires, so there is no change in amount on day 11
+		{ServiceType: "shared", ResourceName: "capacity", AvailabilityZone: "az-one", Amount: 10, ConfirmBy: dayPtr(11), ExpiresAt: limes.UnixEncodedTime{Time: day(21)}, Status: liquid.CommitmentStatusPlanned},
+		// ignored: superseded, or pending without known start
+		{ServiceType: "shared", ResourceName: "capacity", AvailabilityZone: "az-one", Amount: 100, ConfirmedAt: dayPtr(1), ExpiresAt: limes.UnixEncodedTime{Time: day(11)}, Status: liquid.CommitmentStatusSuperseded},
+		{ServiceType: "shared", ResourceName: "capacity", AvailabilityZone: "az-two", Amount: 100, ExpiresAt: limes.UnixEncodedTime{Time: day(11)}, Status: liquid.CommitmentStatusPending},
+		{ServiceType: "shared", ResourceName: "capacity", AvailabilityZone: "az-two", Amount: 3, ConfirmBy: dayPtr(2), ExpiresAt: limes.UnixEncodedTime{Time: day(4)}},
+	}
+
+	series := CommittedAmountTimeSeries(commitments)
+	assert.Equal(t, len(series), 2)
+	assert.DeepEqual(t, "series for az-one", series[scopeOne], []CommittedAmountSample{
+		{At: day(1), Amount: 10},
+		{At: day(5), Amount: 15},
+		{At: day(20), Amount: 10},
+		{At: day(21), Amount: 0},
+	})
+	assert.DeepEqual(t, "series for az-two", series[scopeTwo], []CommittedAmountSample{
+		{At: day(2), Amount: 3},
+		{At: day(4), Amount: 0},
+	})
+}
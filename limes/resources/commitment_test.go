@@ -5,6 +5,7 @@ package limesresources
 
 import (
 	"encoding/json"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -91,3 +92,176 @@ func TestCommitmentDurationAsMapKey(t *testing.T) {
 		t.Errorf("expected decoded map to equal original\n  original: %v\n  decoded:  %v", original, decoded)
 	}
 }
+
+func TestISO8601CommitmentDuration(t *testing.T) {
+	tests := map[string]CommitmentDuration{
+		"P1Y":            {Years: 1},
+		"P1Y2M":          {Years: 1, Months: 2},
+		"P3DT4H":         {Days: 3, Short: 4 * time.Hour},
+		"P2W1D":          {Days: 15},
+		"PT1H30M5S":      {Short: time.Hour + 30*time.Minute + 5*time.Second},
+		"P1Y2M3DT4H5M6S": {Years: 1, Months: 2, Days: 3, Short: 4*time.Hour + 5*time.Minute + 6*time.Second},
+	}
+	canonical := map[string]string{"P2W1D": "P15D"}
+
+	for input, expected := range tests {
+		actual, err := ParseISO8601CommitmentDuration(input)
+		if err != nil {
+			t.Errorf("expected %q to parse, but got error: %s", input, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %q to parse into %#v, but got %#v", input, expected, actual)
+		}
+		expectedStr := input
+		if str, ok := canonical[input]; ok {
+			expectedStr = str
+		}
+		if actualStr, err := actual.ISO8601(); err != nil || actualStr != expectedStr {
+			t.Errorf("expected ISO 8601 representation of %q to be %q, but got %q (err = %v)", input, expectedStr, actualStr, err)
+		}
+	}
+
+	errorTests := map[string]string{
+		"P":      `could not parse CommitmentDuration "P": malformed ISO 8601 duration`,
+		"P1YT":   `could not parse CommitmentDuration "P1YT": malformed ISO 8601 duration`,
+		"PT0S":   `could not parse CommitmentDuration "PT0S": empty duration`,
+		"P1.5Y":  `could not parse CommitmentDuration "P1.5Y": malformed ISO 8601 duration`,
+		"P1D2M":  `could not parse CommitmentDuration "P1D2M": malformed ISO 8601 duration`,
+		"1 year": `could not parse CommitmentDuration "1 year": malformed ISO 8601 duration`,
+		// values that do not fit into time.Duration
+		"PT9999999999H":         `could not parse CommitmentDuration "PT9999999999H": value is too large`,
+		"PT2562047H47M17S":      `could not parse CommitmentDuration "PT2562047H47M17S": value is too large`,
+		"P2000000000000000000W": `could not parse CommitmentDuration "P2000000000000000000W": value is too large`,
+	}
+	for input, expected := range errorTests {
+		_, err := ParseISO8601CommitmentDuration(input)
+		actual := ""
+		if err != nil {
+			actual = err.Error()
+		}
+		if actual != expected {
+			t.Errorf("expected parse of %q to fail with %q, but got %q", input, expected, actual)
+		}
+	}
+
+	// the largest value that fits is accepted
+	actual, err := ParseISO8601CommitmentDuration("PT2562047H47M16S")
+	if err != nil || actual.Short != time.Duration(math.MaxInt64).Truncate(time.Second) {
+		t.Errorf("unexpected parse result for largest duration: %#v (err = %v)", actual, err)
+	}
+
+	// durations that cannot be parsed back are not serialized into ISO 8601
+	unrepresentable := map[CommitmentDuration]string{
+		{Short: 500 * time.Millisecond}: "cannot represent CommitmentDuration in ISO 8601: Short = 500ms is not a whole number of seconds",
+		{}:                              "cannot represent CommitmentDuration in ISO 8601: empty duration",
+		{Days: -1}:                      "cannot represent CommitmentDuration limesresources.CommitmentDuration{Years:0, Months:0, Days:-1, Short:0} in ISO 8601: negative values are not allowed",
+	}
+	for d, expected := range unrepresentable {
+		_, err := d.ISO8601()
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected ISO 8601 representation of %#v to fail with %q, but got %v", d, expected, err)
+		}
+		_, err = json.Marshal(ISO8601CommitmentDuration(d))
+		if err == nil {
+			t.Errorf("expected JSON serialization of %#v to fail", d)
+		}
+	}
+}
+
+func TestCommitmentDurationSerializationSyntaxes(t *testing.T) {
+	type payload struct {
+		Regular CommitmentDuration        `json:"regular"`
+		ISO     ISO8601CommitmentDuration `json:"iso"`
+	}
+
+	// the regular type only accepts its own syntax when deserializing...
+	var d CommitmentDuration
+	err := json.Unmarshal([]byte(`"P1Y"`), &d)
+	if err == nil {
+		t.Errorf("expected ISO 8601 input to be rejected by CommitmentDuration, but got %#v", d)
+	}
+	err = d.UnmarshalYAML(func(target any) error {
+		*(target.(*string)) = "P1Y"
+		return nil
+	})
+	if err == nil {
+		t.Errorf("expected ISO 8601 input to be rejected by CommitmentDuration in YAML, but got %#v", d)
+	}
+	err = d.Scan("PT5M")
+	if err == nil {
+		t.Errorf("expected ISO 8601 input to be rejected by CommitmentDuration.Scan, but got %#v", d)
+	}
+
+	// ...but the ISO 8601 type accepts both syntaxes...
+	var p payload
+	err = json.Unmarshal([]byte(`{"regular":"1 year, 6 months","iso":"2 days, 4 hours"}`), &p)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := payload{
+		Regular: CommitmentDuration{Years: 1, Months: 6},
+		ISO:     ISO8601CommitmentDuration{Days: 2, Short: 4 * time.Hour},
+	}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("expected %#v, but got %#v", expected, p)
+	}
+
+	// ...and both serialize into their respective syntax
+	buf, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(buf) != `{"regular":"1 year, 6 months","iso":"P2DT4H"}` {
+		t.Errorf("unexpected serialization: %s", string(buf))
+	}
+
+	value, err := p.ISO.Value()
+	if err != nil || value != "P2DT4H" {
+		t.Errorf("unexpected SQL value: %#v (err = %v)", value, err)
+	}
+	err = p.ISO.Scan("PT5M")
+	if err != nil || p.ISO != (ISO8601CommitmentDuration{Short: 5 * time.Minute}) {
+		t.Errorf("unexpected result from Scan: %#v (err = %v)", p.ISO, err)
+	}
+	err = p.ISO.UnmarshalText([]byte("P1Y"))
+	if err != nil || p.ISO != (ISO8601CommitmentDuration{Years: 1}) {
+		t.Errorf("unexpected result from UnmarshalText: %#v (err = %v)", p.ISO, err)
+	}
+}
+
+func TestCommitmentDurationOrdering(t *testing.T) {
+	normalized := CommitmentDuration{Years: 1, Months: 18, Days: 40}.Normalize()
+	if normalized != (CommitmentDuration{Years: 2, Months: 6, Days: 40}) {
+		t.Errorf("unexpected result from Normalize: %#v", normalized)
+	}
+
+	// relative to 2026-02-01, one month is shorter than 30 days
+	reference := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	oneMonth := CommitmentDuration{Months: 1}
+	thirtyDays := CommitmentDuration{Days: 30}
+	if oneMonth.Compare(thirtyDays, reference) != -1 {
+		t.Error("expected 1 month < 30 days in February")
+	}
+	// relative to 2026-03-01, it is longer
+	if oneMonth.Compare(thirtyDays, reference.AddDate(0, 1, 0)) != +1 {
+		t.Error("expected 1 month > 30 days in March")
+	}
+
+	durations := []CommitmentDuration{
+		{Years: 1},
+		{Days: 30},
+		{Months: 12},
+		{Months: 1},
+		{Short: time.Hour},
+	}
+	SortCommitmentDurations(durations, reference)
+	var actual []string
+	for _, d := range durations {
+		actual = append(actual, d.String())
+	}
+	expected := []string{"1 hour", "1 month", "30 days", "1 year", "12 months"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected sorted durations to be %v, but got %v", expected, actual)
+	}
+}
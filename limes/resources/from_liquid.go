@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/sapcc/go-api-declarations/internal/errorset"
+	"github.com/sapcc/go-api-declarations/limes"
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+// ProjectServiceReportFromLIQUID converts a usage report from a liquid into the
+// equivalent project service report, without involving the Limes database.
+// This is useful for tools that want to render what a liquid reports in the
+// same way as Limes would. The provided ServiceInfo must be the one that the
+// liquid reported for the InfoVersion of the usage report.
+//
+// Resource names are taken over verbatim. The resulting reports do not
+// contain any data that only Limes knows about, such as commitments or the
+// quota distribution model. Since the only quota value known to the liquid is
+// the backend quota, it is reported in the Quota field. If the backend quota
+// is infinite (i.e. negative), it is instead reported in the BackendQuota
+// field, and the Quota field remains empty.
+//
+// For resources with AZSeparatedTopology, per-AZ quotas are reported in PerAZ
+// and summed up into the resource-level quota. For all other topologies,
+// quota is only reported on the resource level.
+func ProjectServiceReportFromLIQUID(serviceInfo limes.ServiceInfo, info liquid.ServiceInfo, report liquid.ServiceUsageReport) (*ProjectServiceReport, error) {
+	if report.InfoVersion != info.Version {
+		return nil, fmt.Errorf("usage report has InfoVersion = %d, but ServiceInfo has Version = %d", report.InfoVersion, info.Version)
+	}
+
+	var errs errorset.ErrorSet
+	for _, resName := range slices.Sorted(maps.Keys(report.Resources)) {
+		if _, exists := info.Resources[resName]; !exists {
+			errs.Addf("usage report contains resource %q that is not declared in ServiceInfo", resName)
+		}
+	}
+
+	result := &ProjectServiceReport{
+		ServiceInfo: serviceInfo,
+		Resources:   make(ProjectResourceReports, len(info.Resources)),
+	}
+	for _, resName := range slices.Sorted(maps.Keys(info.Resources)) {
+		resInfo := info.Resources[resName]
+		resReport := report.Resources[resName]
+		if resReport == nil {
+			errs.Addf("usage report does not contain resource %q that is declared in ServiceInfo", resName)
+			continue
+		}
+		res, err := projectResourceReportFromLIQUID(resName, resInfo, *resReport)
+		if err != nil {
+			errs.Addf("cannot convert report for resource %q: %w", resName, err)
+			continue
+		}
+		result.Resources[res.Name] = res
+	}
+
+	if !errs.IsEmpty() {
+		return nil, errors.New(errs.Join(", "))
+	}
+	return result, nil
+}
+
+func projectResourceReportFromLIQUID(resName liquid.ResourceName, resInfo liquid.ResourceInfo, resReport liquid.ResourceUsageReport) (*ProjectResourceReport, error) {
+	result := &ProjectResourceReport{
+		ResourceInfo: ResourceInfo{
+			Name:    ResourceName(resName),
+			Unit:    resInfo.Unit,
+			NoQuota: !resInfo.HasQuota,
+		},
+		PerAZ: make(ProjectAZResourceReports, len(resReport.PerAZ)),
+	}
+	if category, ok := resInfo.Category.Unpack(); ok {
+		result.Category = string(category)
+	}
+
+	var (
+		backendQuota       = resReport.Quota.UnwrapOr(0)
+		hasPhysicalUsage   bool
+		totalPhysicalUsage uint64
+		allSubresources    []liquid.Subresource
+	)
+	isAZSeparated := resInfo.Topology == liquid.AZSeparatedTopology
+	for _, az := range slices.Sorted(maps.Keys(resReport.PerAZ)) {
+		azReport := resReport.PerAZ[az]
+		if azReport == nil {
+			continue
+		}
+		azResult := &ProjectAZResourceReport{Usage: azReport.Usage}
+		result.Usage += azReport.Usage
+
+		if physicalUsage, ok := azReport.PhysicalUsage.Unpack(); ok {
+			azResult.PhysicalUsage = &physicalUsage
+			hasPhysicalUsage = true
+			totalPhysicalUsage += physicalUsage
+		} else {
+			totalPhysicalUsage += azReport.Usage
+		}
+
+		if isAZSeparated && resInfo.HasQuota {
+			azQuota := azReport.Quota.UnwrapOr(0)
+			if azQuota >= 0 {
+				azResult.Quota = new(uint64(azQuota))
+			}
+			if azQuota < 0 || backendQuota < 0 {
+				backendQuota = -1
+			} else {
+				backendQuota += azQuota
+			}
+		}
+
+		if len(azReport.Subresources) > 0 {
+			buf, err := json.Marshal(azReport.Subresources)
+			if err != nil {
+				return nil, err
+			}
+			azResult.Subresources = buf
+			allSubresources = append(allSubresources, azReport.Subresources...)
+		}
+		result.PerAZ[az] = azResult
+	}
+
+	if hasPhysicalUsage {
+		result.PhysicalUsage = &totalPhysicalUsage
+	}
+	if resInfo.HasQuota {
+		if backendQuota >= 0 {
+			result.Quota = new(uint64(backendQuota))
+		} else {
+			result.BackendQuota = &backendQuota
+		}
+	}
+	if len(allSubresources) > 0 {
+		buf, err := json.Marshal(allSubresources)
+		if err != nil {
+			return nil, err
+		}
+		result.Subresources = buf
+	}
+	return result, nil
+}
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	th "github.com/sapcc/go-api-declarations/internal/testhelper"
+	"github.com/sapcc/go-api-declarations/limes"
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+var liquidServiceInfo = liquid.ServiceInfo{
+	Version: 3,
+	Resources: map[liquid.ResourceName]liquid.ResourceInfo{
+		"capacity": {
+			Unit:     liquid.UnitGibibytes,
+			Topology: liquid.AZAwareTopology,
+			HasQuota: true,
+			Category: Some(liquid.CategoryName("storage")),
+		},
+		"shares": {
+			Unit:     liquid.UnitPiece,
+			Topology: liquid.AZSeparatedTopology,
+			HasQuota: true,
+		},
+		"snapshots": {
+			Unit:     liquid.UnitNone,
+			Topology: liquid.FlatTopology,
+			HasQuota: true,
+		},
+		"things": {
+			Unit:     liquid.UnitNone,
+			Topology: liquid.FlatTopology,
+		},
+	},
+}
+
+var liquidUsageReport = liquid.ServiceUsageReport{
+	InfoVersion: 3,
+	Resources: map[liquid.ResourceName]*liquid.ResourceUsageReport{
+		"capacity": {
+			Quota: Some[int64](100),
+			PerAZ: map[liquid.AvailabilityZone]*liquid.AZResourceUsageReport{
+				"az-one": {Usage: 20, PhysicalUsage: Some[uint64](5)},
+				"az-two": {
+					Usage: 10,
+					Subresources: []liquid.Subresource{
+						{ID: "share1", Usage: Some[uint64](10)},
+					},
+				},
+			},
+		},
+		"shares": {
+			PerAZ: map[liquid.AvailabilityZone]*liquid.AZResourceUsageReport{
+				"az-one": {Usage: 2, Quota: Some[int64](5)},
+				"az-two": {Usage: 1, Quota: Some[int64](3)},
+			},
+		},
+		"snapshots": {
+			Quota: Some[int64](-1),
+			PerAZ: liquid.InAnyAZ(liquid.AZResourceUsageReport{Usage: 7}),
+		},
+		"things": {
+			PerAZ: liquid.InAnyAZ(liquid.AZResourceUsageReport{
+				Usage:        2,
+				Subresources: []liquid.Subresource{{Name: "foo"}, {Name: "bar"}},
+			}),
+		},
+	},
+}
+
+func TestProjectServiceReportFromLIQUID(t *testing.T) {
+	serviceInfo := limes.ServiceInfo{Type: "sharev2", Area: "storage"}
+	report, err := ProjectServiceReportFromLIQUID(serviceInfo, liquidServiceInfo, liquidUsageReport)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, report.Resources["things"].NoQuota, true)
+	assert.Equal(t, report.Resources["shares"].NoQuota, false)
+
+	th.CheckJSONEquals(t, `{
+		"type": "sharev2",
+		"area": "storage",
+		"resources": [
+			{
+				"name": "capacity",
+				"unit": "GiB",
+				"category": "storage",
+				"per_az": {
+					"az-one": {"usage": 20, "physical_usage": 5},
+					"az-two": {"usage": 10, "subresources": [{"id": "share1", "usage": 10}]}
+				},
+				"quota": 100,
+				"usage": 30,
+				"physical_usage": 15,
+				"subresources": [{"id": "share1", "usage": 10}]
+			},
+			{
+				"name": "shares",
+				"unit": "piece",
+				"per_az": {
+					"az-one": {"quota": 5, "usage": 2},
+					"az-two": {"quota": 3, "usage": 1}
+				},
+				"quota": 8,
+				"usage": 3
+			},
+			{
+				"name": "snapshots",
+				"per_az": {"any": {"usage": 7}},
+				"usage": 7,
+				"backend_quota": -1
+			},
+			{
+				"name": "things",
+				"per_az": {"any": {"usage": 2, "subresources": [{"name": "foo"}, {"name": "bar"}]}},
+				"usage": 2,
+				"subresources": [{"name": "foo"}, {"name": "bar"}]
+			}
+		]
+	}`, report)
+
+	// mismatches between ServiceInfo and report are rejected
+	_, err = ProjectServiceReportFromLIQUID(serviceInfo, liquidServiceInfo, liquid.ServiceUsageReport{InfoVersion: 2})
+	assert.ErrEqual(t, err, "usage report has InfoVersion = 2, but ServiceInfo has Version = 3")
+
+	_, err = ProjectServiceReportFromLIQUID(serviceInfo, liquidServiceInfo, liquid.ServiceUsageReport{
+		InfoVersion: 3,
+		Resources: map[liquid.ResourceName]*liquid.ResourceUsageReport{
+			"capacity":  liquidUsageReport.Resources["capacity"],
+			"shares":    liquidUsageReport.Resources["shares"],
+			"snapshots": liquidUsageReport.Resources["snapshots"],
+			"unknown":   {PerAZ: liquid.InAnyAZ(liquid.AZResourceUsageReport{})},
+		},
+	})
+	assert.ErrEqual(t, err, `usage report contains resource "unknown" that is not declared in ServiceInfo, `+
+		`usage report does not contain resource "things" that is declared in ServiceInfo`)
+}
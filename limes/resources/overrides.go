@@ -4,41 +4,129 @@
 package limesresources
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
+	"github.com/sapcc/go-api-declarations/internal/errorset"
 	"github.com/sapcc/go-api-declarations/limes"
 	"github.com/sapcc/go-api-declarations/liquid"
 )
 
+// QuotaOverrides is the parsed form of a quota-overrides.json file.
+// The keys are domain name, project name, service type and resource name (in that order).
+// The values are quota values in the unit of the respective resource.
+type QuotaOverrides map[string]map[string]map[limes.ServiceType]map[ResourceName]uint64
+
 // ParseQuotaOverrides parses the contents of a quota-overrides.json file.
 // This is the format expected by Limes at $LIMES_QUOTA_OVERRIDES_PATH.
 // This code lives here because it is also used in `limesctl validate-quota-overrides`.
-func ParseQuotaOverrides(buf []byte, getUnit func(limes.ServiceType, ResourceName) (limes.Unit, error)) (result map[string]map[string]map[limes.ServiceType]map[ResourceName]uint64, errs []error) {
+//
+// Errors about individual quota values are prefixed with the line and column
+// where the value appears in the input, e.g. "line 7, column 19: ...".
+func ParseQuotaOverrides(buf []byte, getUnit func(limes.ServiceType, ResourceName) (limes.Unit, error)) (result QuotaOverrides, errs []error) {
 	var parsed map[string]map[string]map[limes.ServiceType]map[ResourceName]json.RawMessage
 	err := json.Unmarshal(buf, &parsed)
+	if err != nil {
+		var (
+			syntaxErr *json.SyntaxError
+			typeErr   *json.UnmarshalTypeError
+		)
+		switch {
+		case errors.As(err, &syntaxErr):
+			// Offset points behind the offending character
+			return nil, []error{fmt.Errorf("%s: %w", positionAtOffset(buf, syntaxErr.Offset-1), err)}
+		case errors.As(err, &typeErr):
+			return nil, []error{fmt.Errorf("%s: %w", positionAtOffset(buf, typeErr.Offset), err)}
+		default:
+			return nil, []error{err}
+		}
+	}
+
+	positions := findQuotaOverridePositions(buf)
+	return parseQuotaOverridesImpl(parsed, getUnit, func(domainName, projectName string, serviceType limes.ServiceType, resourceName ResourceName, err error) error {
+		pos, ok := positions[[4]string{domainName, projectName, string(serviceType), string(resourceName)}]
+		if !ok {
+			return err // defense in depth: should not happen since we found the position of every value
+		}
+		return fmt.Errorf("%s: %w", pos, err)
+	})
+}
+
+// ParseQuotaOverridesYAML is like ParseQuotaOverrides, but for quota overrides in YAML format.
+// Since this module does not depend on any YAML library, the caller must provide the actual YAML decoder:
+//
+//	overrides, errs := limesresources.ParseQuotaOverridesYAML(func(target any) error {
+//		return yaml.Unmarshal(buf, target)
+//	}, getUnit)
+//
+// Since the YAML decoder does not report positions, errors about individual
+// quota values do not contain line and column information.
+func ParseQuotaOverridesYAML(unmarshal func(any) error, getUnit func(limes.ServiceType, ResourceName) (limes.Unit, error)) (result QuotaOverrides, errs []error) {
+	var parsed map[string]map[string]map[limes.ServiceType]map[ResourceName]any
+	err := unmarshal(&parsed)
 	if err != nil {
 		return nil, []error{err}
 	}
 
-	result = make(map[string]map[string]map[limes.ServiceType]map[ResourceName]uint64)
+	// convert values into JSON to reuse the same value parsing logic as for JSON input
+	converted := make(map[string]map[string]map[limes.ServiceType]map[ResourceName]json.RawMessage, len(parsed))
 	for domainName, domainInputs := range parsed {
-		domainResult := make(map[string]map[limes.ServiceType]map[ResourceName]uint64)
+		converted[domainName] = make(map[string]map[limes.ServiceType]map[ResourceName]json.RawMessage, len(domainInputs))
 		for projectName, projectInputs := range domainInputs {
-			projectResult := make(map[limes.ServiceType]map[ResourceName]uint64)
+			converted[domainName][projectName] = make(map[limes.ServiceType]map[ResourceName]json.RawMessage, len(projectInputs))
 			for serviceType, serviceInputs := range projectInputs {
+				serviceConverted := make(map[ResourceName]json.RawMessage, len(serviceInputs))
+				for resourceName, input := range serviceInputs {
+					buf, err := json.Marshal(input)
+					if err != nil {
+						errs = append(errs, fmt.Errorf("unexpected value for %s/%s: %w", serviceType, resourceName, err))
+						continue
+					}
+					serviceConverted[resourceName] = buf
+				}
+				converted[domainName][projectName][serviceType] = serviceConverted
+			}
+		}
+	}
+
+	result, parseErrs := parseQuotaOverridesImpl(converted, getUnit, func(_, _ string, _ limes.ServiceType, _ ResourceName, err error) error {
+		return err
+	})
+	return result, append(errs, parseErrs...)
+}
+
+func parseQuotaOverridesImpl(
+	parsed map[string]map[string]map[limes.ServiceType]map[ResourceName]json.RawMessage,
+	getUnit func(limes.ServiceType, ResourceName) (limes.Unit, error),
+	wrapError func(string, string, limes.ServiceType, ResourceName, error) error,
+) (result QuotaOverrides, errs []error) {
+	result = make(QuotaOverrides)
+	for _, domainName := range slices.Sorted(maps.Keys(parsed)) {
+		domainInputs := parsed[domainName]
+		domainResult := make(map[string]map[limes.ServiceType]map[ResourceName]uint64)
+		for _, projectName := range slices.Sorted(maps.Keys(domainInputs)) {
+			projectInputs := domainInputs[projectName]
+			projectResult := make(map[limes.ServiceType]map[ResourceName]uint64)
+			for _, serviceType := range slices.Sorted(maps.Keys(projectInputs)) {
+				serviceInputs := projectInputs[serviceType]
 				serviceResult := make(map[ResourceName]uint64)
-				for resourceName, inputJSON := range serviceInputs {
+				for _, resourceName := range slices.Sorted(maps.Keys(serviceInputs)) {
+					inputJSON := serviceInputs[resourceName]
 					unit, err := getUnit(serviceType, resourceName)
 					if err != nil {
-						errs = append(errs, err)
+						errs = append(errs, wrapError(domainName, projectName, serviceType, resourceName, err))
 						continue
 					}
 					value, err := parseSingleQuotaOverrideValue(inputJSON, serviceType, resourceName, unit)
 					if err == nil {
 						serviceResult[resourceName] = value
 					} else {
-						errs = append(errs, err)
+						errs = append(errs, wrapError(domainName, projectName, serviceType, resourceName, err))
 					}
 				}
 				projectResult[serviceType] = serviceResult
@@ -51,25 +139,184 @@ func ParseQuotaOverrides(buf []byte, getUnit func(limes.ServiceType, ResourceNam
 }
 
 func parseSingleQuotaOverrideValue(input json.RawMessage, serviceType limes.ServiceType, resourceName ResourceName, unit limes.Unit) (uint64, error) {
+	var q limes.Quantity
+	err := json.Unmarshal([]byte(input), &q)
+	isString := bytes.HasPrefix(input, []byte(`"`))
+
 	// case 1: counted resources represent quota as a single number
-	if unit == limes.UnitNone || unit == liquid.UnitPiece {
-		var value uint64
-		err := json.Unmarshal([]byte(input), &value)
-		if err != nil {
+	if isCountedUnit(unit) {
+		if err != nil || isString {
 			return 0, fmt.Errorf("expected uint64 value for %s/%s, but got %q", serviceType, resourceName, string(input))
 		}
-		return value, nil
+		return q.Value, nil
 	}
 
 	// case 2: measured resources represent quota as a string of value with unit
-	var value string
-	err := json.Unmarshal([]byte(input), &value)
-	if err != nil {
+	if !isString {
 		return 0, fmt.Errorf("expected string field for %s/%s, but got %q", serviceType, resourceName, string(input))
 	}
-	parsedValue, err := limes.ParseInUnit(unit, value)
 	if err != nil {
 		return 0, fmt.Errorf("in value for %s/%s: %w", serviceType, resourceName, err)
 	}
-	return parsedValue, nil
+	value, err := q.ValueInUnit(unit)
+	if err != nil {
+		return 0, fmt.Errorf("in value for %s/%s: %w", serviceType, resourceName, err)
+	}
+	return value, nil
+}
+
+func isCountedUnit(unit limes.Unit) bool {
+	return unit == limes.UnitNone || unit == liquid.UnitPiece
+}
+
+// Marshal serializes these quota overrides into the format accepted by func ParseQuotaOverrides.
+// Values of counted resources are represented as plain numbers. Values of
+// measured resources are represented as strings with the most compact unit,
+// e.g. "5 GiB" instead of "5120 MiB".
+func (o QuotaOverrides) Marshal(getUnit func(limes.ServiceType, ResourceName) (limes.Unit, error)) ([]byte, error) {
+	var errs errorset.ErrorSet
+	output := make(map[string]map[string]map[limes.ServiceType]map[ResourceName]any, len(o))
+	for domainName, domainValues := range o {
+		output[domainName] = make(map[string]map[limes.ServiceType]map[ResourceName]any, len(domainValues))
+		for projectName, projectValues := range domainValues {
+			output[domainName][projectName] = make(map[limes.ServiceType]map[ResourceName]any, len(projectValues))
+			for serviceType, serviceValues := range projectValues {
+				serviceOutput := make(map[ResourceName]any, len(serviceValues))
+				for resourceName, value := range serviceValues {
+					unit, err := getUnit(serviceType, resourceName)
+					switch {
+					case err != nil:
+						errs.Add(err)
+					case isCountedUnit(unit):
+						serviceOutput[resourceName] = value
+					default:
+						serviceOutput[resourceName] = limes.ValueWithUnit{Value: value, Unit: unit}.String()
+					}
+				}
+				output[domainName][projectName][serviceType] = serviceOutput
+			}
+		}
+	}
+	if !errs.IsEmpty() {
+		slices.SortFunc(errs, func(lhs, rhs error) int { return strings.Compare(lhs.Error(), rhs.Error()) }) // for deterministic output
+		return nil, errors.New(errs.Join(", "))
+	}
+	return json.MarshalIndent(output, "", "  ")
+}
+
+// MergeQuotaOverrides merges quota overrides from multiple files into one.
+// The map keys are the file names, which are used in error messages.
+// If the same resource in the same project appears in multiple files with
+// different values, an error is reported for each such conflict, and the
+// value from the file that sorts first by name is used.
+func MergeQuotaOverrides(files map[string]QuotaOverrides) (result QuotaOverrides, errs []error) {
+	type origin struct {
+		FileName string
+		Value    uint64
+	}
+	origins := make(map[[4]string]origin)
+
+	result = make(QuotaOverrides)
+	for _, fileName := range slices.Sorted(maps.Keys(files)) {
+		for domainName, domainValues := range files[fileName] {
+			if result[domainName] == nil {
+				result[domainName] = make(map[string]map[limes.ServiceType]map[ResourceName]uint64)
+			}
+			for projectName, projectValues := range domainValues {
+				if result[domainName][projectName] == nil {
+					result[domainName][projectName] = make(map[limes.ServiceType]map[ResourceName]uint64)
+				}
+				for serviceType, serviceValues := range projectValues {
+					if result[domainName][projectName][serviceType] == nil {
+						result[domainName][projectName][serviceType] = make(map[ResourceName]uint64)
+					}
+					for resourceName, value := range serviceValues {
+						key := [4]string{domainName, projectName, string(serviceType), string(resourceName)}
+						if prev, exists := origins[key]; exists {
+							if prev.Value != value {
+								errs = append(errs, fmt.Errorf("conflicting values for %s/%s in project %s/%s: %d in %s, but %d in %s",
+									serviceType, resourceName, domainName, projectName, prev.Value, prev.FileName, value, fileName))
+							}
+							continue
+						}
+						origins[key] = origin{fileName, value}
+						result[domainName][projectName][serviceType][resourceName] = value
+					}
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(errs, func(lhs, rhs error) int { return strings.Compare(lhs.Error(), rhs.Error()) }) // for deterministic output
+	return result, errs
+}
+
+// textPosition is a position in a text file, as reported in parse errors.
+type textPosition struct {
+	Line   int
+	Column int
+}
+
+// String implements the fmt.Stringer interface.
+func (p textPosition) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// positionAtOffset converts a byte offset into a line and column number (both 1-based).
+func positionAtOffset(buf []byte, offset int64) textPosition {
+	offset = min(max(offset, 0), int64(len(buf)))
+	before := buf[:offset]
+	return textPosition{
+		Line:   bytes.Count(before, []byte("\n")) + 1,
+		Column: len(before) - bytes.LastIndexByte(before, '\n'),
+	}
+}
+
+// findQuotaOverridePositions finds the positions of all values in a quota overrides file in JSON format.
+// The map keys are domain name, project name, service type and resource name.
+// The input must already be known to be syntactically valid JSON.
+func findQuotaOverridePositions(buf []byte) map[[4]string]textPosition {
+	result := make(map[[4]string]textPosition)
+	dec := json.NewDecoder(bytes.NewReader(buf))
+
+	var walk func(path []string) error
+	walk = func(path []string) error {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil // scalar value
+		}
+
+		for dec.More() {
+			childPath := path
+			if delim == '{' {
+				token, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := token.(string)
+				childPath = append(slices.Clone(path), key)
+				if len(childPath) == 4 {
+					// the value starts after the colon and any whitespace following the key
+					offset := dec.InputOffset()
+					for offset < int64(len(buf)) && bytes.IndexByte([]byte(" \t\r\n:"), buf[offset]) >= 0 {
+						offset++
+					}
+					result[[4]string(childPath)] = positionAtOffset(buf, offset)
+				}
+			}
+			err := walk(childPath)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token() // consume the closing delimiter
+		return err
+	}
+
+	_ = walk(nil) // errors can only occur on malformed inputs, in which case we do not report positions
+	return result
 }
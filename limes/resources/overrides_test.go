@@ -5,30 +5,32 @@ package limesresources
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/sapcc/go-api-declarations/limes"
 	"github.com/sapcc/go-api-declarations/liquid"
 )
 
-func TestParseQuotaOverrides(t *testing.T) {
-	// mock implementation of getUnit callback presenting two example resources
-	getUnit := func(serviceType limes.ServiceType, resourceName ResourceName) (limes.Unit, error) {
-		switch fmt.Sprintf("%s/%s", serviceType, resourceName) {
-		case "unittest/capacity":
-			return limes.UnitBytes, nil
-		case "unittest/things":
-			// For full realism, this must return UnitPiece instead of UnitNone.
-			// Under productive use, getUnit will take metadata from the Limes DB, where UnitNone is normalized into UnitPiece.
-			return liquid.UnitPiece, nil
-		default:
-			return limes.UnitNone, fmt.Errorf("%s/%s is not a valid resource", serviceType, resourceName)
-		}
+// mock implementation of getUnit callback presenting two example resources
+func getUnitForOverridesTest(serviceType limes.ServiceType, resourceName ResourceName) (limes.Unit, error) {
+	switch fmt.Sprintf("%s/%s", serviceType, resourceName) {
+	case "unittest/capacity":
+		return limes.UnitBytes, nil
+	case "unittest/things":
+		// For full realism, this must return UnitPiece instead of UnitNone.
+		// Under productive use, getUnit will take metadata from the Limes DB, where UnitNone is normalized into UnitPiece.
+		return liquid.UnitPiece, nil
+	default:
+		return limes.UnitNone, fmt.Errorf("%s/%s is not a valid resource", serviceType, resourceName)
 	}
+}
 
+func TestParseQuotaOverrides(t *testing.T) {
 	// test successful parsing
 	buf := []byte(`
 		{
@@ -42,9 +44,9 @@ func TestParseQuotaOverrides(t *testing.T) {
 			}
 		}
 	`)
-	result, errs := ParseQuotaOverrides(buf, getUnit)
+	result, errs := ParseQuotaOverrides(buf, getUnitForOverridesTest)
 	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{})
-	assertDeepEqual(t, "result", result, map[string]map[string]map[limes.ServiceType]map[ResourceName]uint64{
+	assertDeepEqual(t, "result", result, QuotaOverrides{
 		"domain-one": {
 			"project-one": {
 				"unittest": {
@@ -83,12 +85,180 @@ func TestParseQuotaOverrides(t *testing.T) {
 		}
 	`)
 	buf = bytes.ReplaceAll(buf, []byte("\t"), []byte("  "))
-	_, errs = ParseQuotaOverrides(buf, getUnit)
+	_, errs = ParseQuotaOverrides(buf, getUnitForOverridesTest)
+	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{
+		`line 11, column 23: expected uint64 value for unittest/things, but got "\"50 GiB\""`,
+		`line 16, column 33: unittest/unknown-resource is not a valid resource`,
+		`line 21, column 22: unknown-service/items is not a valid resource`,
+		`line 6, column 25: expected string field for unittest/capacity, but got "[ 1, \"GiB\" ]"`,
+	})
+}
+
+func TestParseQuotaOverrideValues(t *testing.T) {
+	buf := []byte(`{
+		"domain-one": {
+			"project-one": { "unittest": { "capacity": "1.5 GiB" } },
+			"project-two": { "unittest": { "capacity": "42" } },
+			"project-three": { "unittest": { "capacity": "5 bananas" } },
+			"project-four": { "unittest": { "capacity": "0.5 B" } },
+			"project-five": { "unittest": { "things": "20" } }
+		}
+	}`)
+	buf = bytes.ReplaceAll(buf, []byte("\t"), []byte("  "))
+	result, errs := ParseQuotaOverrides(buf, getUnitForOverridesTest)
+	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{
+		`line 4, column 50: in value for unittest/capacity: missing unit in value "42" (expected a value in B or a compatible unit)`,
+		`line 5, column 52: in value for unittest/capacity: invalid value "5 bananas": no such unit`,
+		`line 6, column 51: in value for unittest/capacity: value "0.5 B" cannot be represented as integer number of B (would be 0.5 B)`,
+		`line 7, column 49: expected uint64 value for unittest/things, but got "\"20\""`,
+	})
+	assertDeepEqual(t, "result", result["domain-one"]["project-one"], map[limes.ServiceType]map[ResourceName]uint64{
+		"unittest": {"capacity": 3 << 29},
+	})
+}
+
+func TestParseQuotaOverridesSyntaxError(t *testing.T) {
+	buf := []byte("{\n  \"domain-one\": {\n    \"project-one\": [}\n}")
+	_, errs := ParseQuotaOverrides(buf, nil)
+	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{
+		`line 3, column 21: invalid character '}' looking for beginning of value`,
+	})
+
+	// the exact error message depends on the Go version, so we only check the position
+	buf = []byte("{\n  \"domain-one\": 42\n}")
+	_, errs = ParseQuotaOverrides(buf, nil)
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "line 2, column 19: json: cannot unmarshal number") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestParseQuotaOverridesYAML(t *testing.T) {
+	// mock implementation of a YAML decoder, with the same value types that a YAML library would produce
+	unmarshal := func(target any) error {
+		*(target.(*map[string]map[string]map[limes.ServiceType]map[ResourceName]any)) = map[string]map[string]map[limes.ServiceType]map[ResourceName]any{
+			"domain-one": {
+				"project-one": {
+					"unittest": {
+						"things":   20,
+						"capacity": "5 GiB",
+					},
+				},
+				"project-two": {
+					"unittest": {
+						"things":   map[string]any{"foo": "bar"},
+						"capacity": 42,
+					},
+				},
+			},
+		}
+		return nil
+	}
+
+	result, errs := ParseQuotaOverridesYAML(unmarshal, getUnitForOverridesTest)
 	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{
-		`expected string field for unittest/capacity, but got "[ 1, \"GiB\" ]"`,
-		`expected uint64 value for unittest/things, but got "\"50 GiB\""`,
-		`unittest/unknown-resource is not a valid resource`,
-		`unknown-service/items is not a valid resource`,
+		`expected string field for unittest/capacity, but got "42"`,
+		`expected uint64 value for unittest/things, but got "{\"foo\":\"bar\"}"`,
+	})
+	assertDeepEqual(t, "result", result, QuotaOverrides{
+		"domain-one": {
+			"project-one": {
+				"unittest": {
+					"things":   20,
+					"capacity": 5 << 30,
+				},
+			},
+			"project-two": {
+				"unittest": {},
+			},
+		},
+	})
+
+	_, errs = ParseQuotaOverridesYAML(func(any) error { return errors.New("malformed YAML") }, getUnitForOverridesTest)
+	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{"malformed YAML"})
+}
+
+func TestMarshalQuotaOverrides(t *testing.T) {
+	overrides := QuotaOverrides{
+		"domain-one": {
+			"project-one": {
+				"unittest": {
+					"things":   20,
+					"capacity": 5 << 30,
+				},
+			},
+			"project-two": {
+				"unittest": {
+					"capacity": 1536 << 20,
+				},
+			},
+		},
+	}
+	buf, err := overrides.Marshal(getUnitForOverridesTest)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := `{
+  "domain-one": {
+    "project-one": {
+      "unittest": {
+        "capacity": "5 GiB",
+        "things": 20
+      }
+    },
+    "project-two": {
+      "unittest": {
+        "capacity": "1536 MiB"
+      }
+    }
+  }
+}`
+	assertDeepEqual(t, "marshaled", string(buf), expected)
+
+	// the result can be parsed again
+	parsed, errs := ParseQuotaOverrides(buf, getUnitForOverridesTest)
+	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{})
+	assertDeepEqual(t, "parsed", parsed, overrides)
+
+	overrides["domain-one"]["project-one"]["unknown-service"] = map[ResourceName]uint64{"items": 10}
+	_, err = overrides.Marshal(getUnitForOverridesTest)
+	assertDeepEqual(t, "error", err.Error(), "unknown-service/items is not a valid resource")
+}
+
+func TestMergeQuotaOverrides(t *testing.T) {
+	files := map[string]QuotaOverrides{
+		"a.json": {
+			"domain-one": {
+				"project-one": {"unittest": {"things": 20, "capacity": 1024}},
+			},
+		},
+		"b.json": {
+			"domain-one": {
+				"project-one": {"unittest": {"things": 20}},
+				"project-two": {"unittest": {"things": 5}},
+			},
+			"domain-two": {
+				"project-three": {"unittest": {"capacity": 2048}},
+			},
+		},
+		"c.json": {
+			"domain-one": {
+				"project-one": {"unittest": {"capacity": 4096}},
+			},
+		},
+	}
+
+	result, errs := MergeQuotaOverrides(files)
+	assertDeepEqual(t, "errors", errorsToStrings(errs), []string{
+		"conflicting values for unittest/capacity in project domain-one/project-one: 1024 in a.json, but 4096 in c.json",
+	})
+	assertDeepEqual(t, "result", result, QuotaOverrides{
+		"domain-one": {
+			"project-one": {"unittest": {"things": 20, "capacity": 1024}},
+			"project-two": {"unittest": {"things": 5}},
+		},
+		"domain-two": {
+			"project-three": {"unittest": {"capacity": 2048}},
+		},
 	})
 }
 
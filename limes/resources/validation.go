@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/internal/errorset"
+)
+
+// ValidateCommitmentRequest checks that the provided CommitmentRequest is
+// acceptable for a resource with the provided CommitmentConfiguration, as far
+// as this can be decided without asking Limes. Currently, this means that:
+//
+//   - The Amount must not be zero.
+//   - The Duration must be one of the Durations allowed by the CommitmentConfiguration.
+//   - ConfirmBy (if any) must not be before the given point in time `now`.
+//   - ConfirmBy (if any) must not be before MinConfirmBy (if any).
+//   - NotifyOnConfirm can only be set if ConfirmBy is filled.
+//
+// Additional validations may be added in the future.
+// Even if this validation succeeds, Limes may still reject the request,
+// for instance because there is not enough capacity to confirm it.
+func ValidateCommitmentRequest(req CommitmentRequest, cfg CommitmentConfiguration, now time.Time) error {
+	errs := validateCommitmentRequestImpl(req, cfg, now)
+	if len(errs) > 0 {
+		// NOTE: Errors get joined with "; " instead of ", " because some errors contain commas themselves.
+		return fmt.Errorf("CommitmentRequest is invalid: %s", errs.Join("; "))
+	}
+	return nil
+}
+
+// This is the function that the unit tests call. An ErrorSet is easier to compare against fixtures than the final stringified error.
+func validateCommitmentRequestImpl(req CommitmentRequest, cfg CommitmentConfiguration, now time.Time) (errs errorset.ErrorSet) {
+	if req.Amount == 0 {
+		errs.Addf("invalid value for .Amount (expected a positive number, but got 0)")
+	}
+
+	switch {
+	case len(cfg.Durations) == 0:
+		errs.Addf("invalid value for .Duration (resource does not accept commitments)")
+	case !slices.Contains(cfg.Durations, req.Duration):
+		allowed := make([]string, len(cfg.Durations))
+		for idx, d := range cfg.Durations {
+			allowed[idx] = strconv.Quote(d.String())
+		}
+		errs.Addf("invalid value for .Duration (expected one of %s, but got %q)", strings.Join(allowed, " or "), req.Duration.String())
+	}
+
+	if req.ConfirmBy != nil {
+		confirmBy := req.ConfirmBy.Time
+		if confirmBy.Before(now) {
+			errs.Addf("invalid value for .ConfirmBy: %s is in the past (now = %s)",
+				confirmBy.Format(time.RFC3339), now.Format(time.RFC3339))
+		}
+		if cfg.MinConfirmBy != nil && confirmBy.Before(cfg.MinConfirmBy.Time) {
+			errs.Addf("invalid value for .ConfirmBy: %s is before MinConfirmBy = %s",
+				confirmBy.Format(time.RFC3339), cfg.MinConfirmBy.Format(time.RFC3339))
+		}
+	} else if req.NotifyOnConfirm {
+		errs.Addf("unexpected value for .NotifyOnConfirm (can only be set if .ConfirmBy is filled)")
+	}
+
+	return errs
+}
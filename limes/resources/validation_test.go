@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package limesresources
+
+import (
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/go-api-declarations/limes"
+)
+
+func TestValidateCommitmentRequest(t *testing.T) {
+	oneYear := mustParseDuration(t, "1 year")
+	threeYears := mustParseDuration(t, "3 years")
+	cfg := CommitmentConfiguration{
+		Durations:    []CommitmentDuration{oneYear, threeYears},
+		MinConfirmBy: dayPtr(15),
+	}
+	now := day(10)
+
+	// valid requests
+	req := CommitmentRequest{
+		ServiceType:      "shared",
+		ResourceName:     "capacity",
+		AvailabilityZone: "az-one",
+		Amount:           10,
+		Duration:         oneYear,
+	}
+	assert.ErrEqual(t, ValidateCommitmentRequest(req, cfg, now), nil)
+	req.ConfirmBy = dayPtr(20)
+	req.NotifyOnConfirm = true
+	assert.ErrEqual(t, ValidateCommitmentRequest(req, cfg, now), nil)
+
+	// invalid requests
+	req = CommitmentRequest{
+		ServiceType:      "shared",
+		ResourceName:     "capacity",
+		AvailabilityZone: "az-one",
+		Amount:           0,
+		Duration:         mustParseDuration(t, "2 years"),
+		NotifyOnConfirm:  true,
+	}
+	assert.DeepEqual(t, "errors", validateCommitmentRequestImpl(req, cfg, now).Join("\n"), `invalid value for .Amount (expected a positive number, but got 0)
+invalid value for .Duration (expected one of "1 year" or "3 years", but got "2 years")
+unexpected value for .NotifyOnConfirm (can only be set if .ConfirmBy is filled)`)
+
+	req = CommitmentRequest{
+		ServiceType:      "shared",
+		ResourceName:     "capacity",
+		AvailabilityZone: "az-one",
+		Amount:           10,
+		Duration:         oneYear,
+		ConfirmBy:        dayPtr(5),
+	}
+	assert.ErrEqual(t, ValidateCommitmentRequest(req, cfg, now), "CommitmentRequest is invalid: "+
+		"invalid value for .ConfirmBy: 2026-01-05T00:00:00Z is in the past (now = 2026-01-10T00:00:00Z); "+
+		"invalid value for .ConfirmBy: 2026-01-05T00:00:00Z is before MinConfirmBy = 2026-01-15T00:00:00Z")
+
+	req.ConfirmBy = &limes.UnixEncodedTime{Time: day(12)}
+	assert.ErrEqual(t, ValidateCommitmentRequest(req, cfg, now), "CommitmentRequest is invalid: "+
+		"invalid value for .ConfirmBy: 2026-01-12T00:00:00Z is before MinConfirmBy = 2026-01-15T00:00:00Z")
+
+	// resources without commitment durations do not accept commitments at all
+	req.ConfirmBy = nil
+	assert.ErrEqual(t, ValidateCommitmentRequest(req, CommitmentConfiguration{}, now), "CommitmentRequest is invalid: "+
+		"invalid value for .Duration (resource does not accept commitments)")
+}
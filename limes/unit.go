@@ -28,20 +28,79 @@ var (
 	UnitPebibytes = units.UnitPebibytes
 	// UnitExbibytes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
 	UnitExbibytes = units.UnitExbibytes
+
+	// UnitKilobytes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitKilobytes = units.UnitKilobytes
+	// UnitMegabytes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitMegabytes = units.UnitMegabytes
+	// UnitGigabytes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitGigabytes = units.UnitGigabytes
+	// UnitTerabytes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitTerabytes = units.UnitTerabytes
+	// UnitPetabytes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitPetabytes = units.UnitPetabytes
+	// UnitExabytes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitExabytes = units.UnitExabytes
+
+	// UnitBits is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitBits = units.UnitBits
+	// UnitKilobits is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitKilobits = units.UnitKilobits
+	// UnitMegabits is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitMegabits = units.UnitMegabits
+	// UnitGigabits is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitGigabits = units.UnitGigabits
+	// UnitTerabits is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitTerabits = units.UnitTerabits
+	// UnitPetabits is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitPetabits = units.UnitPetabits
+	// UnitExabits is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitExabits = units.UnitExabits
+
+	// UnitSeconds is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitSeconds = units.UnitSeconds
+	// UnitMinutes is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitMinutes = units.UnitMinutes
+	// UnitHours is exactly that. Its MultiplyBy() method can be used to instantiate non-standard units.
+	UnitHours = units.UnitHours
 )
 
 // ParseInUnit parses the string representation of a value with this unit
-// (or any unit that can be converted to it).
+// (or any unit that can be converted to it). Fractional numbers are accepted
+// as long as the result is an integer in the target unit.
 //
-//	ParseInUnit(UnitMebibytes, "10 MiB") -> 10
-//	ParseInUnit(UnitMebibytes, "10 GiB") -> 10240
-//	ParseInUnit(UnitMebibytes, "10 KiB") -> error: incompatible unit
-//	ParseInUnit(UnitMebibytes, "10")     -> error: missing unit
-//	ParseInUnit(UnitNone, "42")          -> 42
-//	ParseInUnit(UnitNone, "42 MiB")      -> error: unexpected unit
+//	ParseInUnit(UnitMebibytes, "10 MiB")  -> 10
+//	ParseInUnit(UnitMebibytes, "10 GiB")  -> 10240
+//	ParseInUnit(UnitMebibytes, "10 KiB")  -> error: incompatible unit
+//	ParseInUnit(UnitMebibytes, "10")      -> error: missing unit
+//	ParseInUnit(UnitKilobytes, "125 KiB") -> 128
+//	ParseInUnit(UnitKibibytes, "1 MB")    -> error: not an integer number of KiB
+//	ParseInUnit(UnitGibibytes, "1.5 TiB") -> 1536
+//	ParseInUnit(UnitGibibytes, "1.3 GiB") -> error: not an integer number of GiB
+//	ParseInUnit(UnitNone, "42")           -> 42
+//	ParseInUnit(UnitNone, "42 MiB")       -> error: unexpected unit
 func ParseInUnit(u Unit, str string) (uint64, error) {
 	return units.ParseInUnit(u, str)
 }
 
 // ValueWithUnit is used to represent values with units in subresources.
 type ValueWithUnit = units.LimesV1ValueWithUnit
+
+// Quantity is a value with a unit that supports checked arithmetic across compatible units:
+//
+//	sum, err := Quantity{Value: 2, Unit: UnitGibibytes}.Add(Quantity{Value: 512, Unit: UnitMebibytes})
+//	// -> Quantity{Value: 2560, Unit: UnitMebibytes}
+//
+// When combining quantities in different units, the result is expressed in
+// the largest unit that both units are a multiple of. Errors are returned
+// on integer overflow, or when units are incompatible (e.g. UnitNone and UnitBytes).
+//
+// Quantity can be used in config types to accept either bare numbers (for
+// counted resources) or values with unit like "10 GiB" (for measured
+// resources) from JSON, YAML, TOML or text input. Use the ValueInUnit() method
+// to obtain the value in the unit of the respective resource. When encoding
+// into JSON, YAML or text, the same formats are produced, so that decoding
+// restores the original Quantity, except for units with a numeric multiplier
+// (e.g. "4 MiB"): Those are encoded in the most appropriate unit, so decoding
+// yields the same amount, but with a different Unit.
+type Quantity = units.Quantity
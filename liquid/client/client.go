@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package liquidclient contains a typed HTTP client for LIQUID.
+// Each endpoint that is documented in package liquid is available as a method on type [Client].
+package liquidclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+// Opts contains optional configuration for a [Client].
+type Opts struct {
+	// The HTTP client that is used to send requests.
+	// If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// If not nil, this function is called before each request to obtain the Keystone token that goes into the "X-Auth-Token" header.
+	// If nil, requests are sent without that header.
+	GetToken func(ctx context.Context) (string, error)
+
+	// If true, all responses are validated using the respective functions in package liquid before they are returned:
+	//   - GetInfo() checks its result with ValidateServiceInfo().
+	//   - GetCapacityReport() checks its result with ValidateCapacityReport().
+	//   - GetUsageReport() checks its result with ValidateUsageReport().
+	//
+	// The latter two need a ServiceInfo to validate against.
+	// The Client remembers the ServiceInfo from the most recent GetInfo() call, and will call GetInfo() implicitly
+	// if no ServiceInfo has been obtained yet or if the report has a different InfoVersion.
+	ValidateResponses bool
+}
+
+// Client is a typed HTTP client for LIQUID.
+// It is safe for concurrent use.
+type Client struct {
+	baseURL *url.URL
+	opts    Opts
+
+	// cache for func getServiceInfoForValidation
+	infoMutex sync.Mutex
+	info      Option[liquid.ServiceInfo]
+}
+
+// New builds a Client for the liquid with the given base URL.
+// The base URL is usually obtained from the Keystone service catalog.
+func New(baseURL string, opts Opts) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse LIQUID base URL %q: %w", baseURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("cannot parse LIQUID base URL %q: not an absolute URL", baseURL)
+	}
+	if u.Path == "" {
+		u.Path = "/" // otherwise JoinPath() in func do() produces relative paths
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &Client{baseURL: u, opts: opts}, nil
+}
+
+// GetInfo executes GET /v1/info.
+func (c *Client) GetInfo(ctx context.Context) (liquid.ServiceInfo, error) {
+	var result liquid.ServiceInfo
+	err := c.do(ctx, http.MethodGet, "v1/info", nil, &result)
+	if err != nil {
+		return liquid.ServiceInfo{}, err
+	}
+	if c.opts.ValidateResponses {
+		err = liquid.ValidateServiceInfo(result)
+		if err != nil {
+			return liquid.ServiceInfo{}, err
+		}
+	}
+
+	c.infoMutex.Lock()
+	defer c.infoMutex.Unlock()
+	c.info = Some(result.Clone())
+	return result, nil
+}
+
+// GetCapacityReport executes POST /v1/report-capacity.
+func (c *Client) GetCapacityReport(ctx context.Context, req liquid.ServiceCapacityRequest) (liquid.ServiceCapacityReport, error) {
+	var result liquid.ServiceCapacityReport
+	err := c.do(ctx, http.MethodPost, "v1/report-capacity", req, &result)
+	if err != nil {
+		return liquid.ServiceCapacityReport{}, err
+	}
+	if c.opts.ValidateResponses {
+		info, err := c.getServiceInfoForValidation(ctx, result.InfoVersion)
+		if err != nil {
+			return liquid.ServiceCapacityReport{}, err
+		}
+		err = liquid.ValidateCapacityReport(result, req, info)
+		if err != nil {
+			return liquid.ServiceCapacityReport{}, err
+		}
+	}
+	return result, nil
+}
+
+// GetUsageReport executes POST /v1/projects/:uuid/report-usage.
+func (c *Client) GetUsageReport(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceUsageRequest) (liquid.ServiceUsageReport, error) {
+	var result liquid.ServiceUsageReport
+	err := c.do(ctx, http.MethodPost, "v1/projects/"+url.PathEscape(string(projectUUID))+"/report-usage", req, &result)
+	if err != nil {
+		return liquid.ServiceUsageReport{}, err
+	}
+	if c.opts.ValidateResponses {
+		info, err := c.getServiceInfoForValidation(ctx, result.InfoVersion)
+		if err != nil {
+			return liquid.ServiceUsageReport{}, err
+		}
+		err = liquid.ValidateUsageReport(result, req, info)
+		if err != nil {
+			return liquid.ServiceUsageReport{}, err
+		}
+	}
+	return result, nil
+}
+
+// PutQuota executes PUT /v1/projects/:uuid/quota.
+func (c *Client) PutQuota(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceQuotaRequest) error {
+	return c.do(ctx, http.MethodPut, "v1/projects/"+url.PathEscape(string(projectUUID))+"/quota", req, nil)
+}
+
+// ChangeCommitments executes POST /v1/change-commitments.
+func (c *Client) ChangeCommitments(ctx context.Context, req liquid.CommitmentChangeRequest) (liquid.CommitmentChangeResponse, error) {
+	var result liquid.CommitmentChangeResponse
+	err := c.do(ctx, http.MethodPost, "v1/change-commitments", req, &result)
+	if err != nil {
+		return liquid.CommitmentChangeResponse{}, err
+	}
+	return result, nil
+}
+
+func (c *Client) getServiceInfoForValidation(ctx context.Context, infoVersion int64) (liquid.ServiceInfo, error) {
+	c.infoMutex.Lock()
+	info, ok := c.info.Unpack()
+	c.infoMutex.Unlock()
+	if ok && info.Version == infoVersion {
+		return info, nil
+	}
+	// if the report has a different InfoVersion than our cached ServiceInfo, the ServiceInfo might have changed;
+	// if refetching does not fix the mismatch, validation will report it
+	return c.GetInfo(ctx)
+}
+
+// Sends a request and decodes the response body into `responseBody`.
+// If `responseBody` is nil, a 204 (No Content) response is expected.
+func (c *Client) do(ctx context.Context, method, subpath string, requestBody, responseBody any) error {
+	// NOTE: This uses a relative reference such that a base URL with a subpath (e.g. "https://example.com/liquid-foo/") works as expected.
+	u := c.baseURL.JoinPath(subpath)
+
+	var bodyReader io.Reader
+	if requestBody != nil {
+		buf, err := json.Marshal(requestBody)
+		if err != nil {
+			return fmt.Errorf("cannot encode request body for %s %s: %w", method, u.Path, err)
+		}
+		bodyReader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+	if err != nil {
+		return fmt.Errorf("cannot build request for %s %s: %w", method, u.Path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if requestBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.opts.GetToken != nil {
+		token, err := c.opts.GetToken(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot obtain token for %s %s: %w", method, u.Path, err)
+		}
+		req.Header.Set("X-Auth-Token", token)
+	}
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("during %s %s: %w", method, u.Path, err)
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read response body for %s %s: %w", method, u.Path, err)
+	}
+
+	expectedStatus := http.StatusOK
+	if responseBody == nil {
+		expectedStatus = http.StatusNoContent
+	}
+	if resp.StatusCode != expectedStatus {
+		return ResponseError{
+			Method:     method,
+			Path:       u.Path,
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(buf)),
+		}
+	}
+
+	if responseBody != nil {
+		err = json.Unmarshal(buf, responseBody)
+		if err != nil {
+			return fmt.Errorf("cannot decode response body for %s %s: %w", method, u.Path, err)
+		}
+	}
+	return nil
+}
+
+var (
+	// ErrUnauthorized is wrapped by a [ResponseError] with status 401 (Unauthorized).
+	ErrUnauthorized = errors.New("token was not accepted")
+	// ErrForbidden is wrapped by a [ResponseError] with status 403 (Forbidden).
+	ErrForbidden = errors.New("token does not confer sufficient access")
+	// ErrClientSide is wrapped by a [ResponseError] with any other status in the 4xx range.
+	ErrClientSide = errors.New("request was rejected by the liquid")
+	// ErrServerSide is wrapped by a [ResponseError] with a status in the 5xx range.
+	ErrServerSide = errors.New("liquid encountered an internal error")
+	// ErrUnexpectedStatus is wrapped by a [ResponseError] with any status that is neither in the 4xx nor in the 5xx range.
+	ErrUnexpectedStatus = errors.New("liquid returned an unexpected status")
+)
+
+// ResponseError is returned by the methods of [Client] when the liquid responds with an unexpected status code.
+//
+// The class of status code can be checked with errors.Is() against the Err... variables in this package.
+// For example, errors.Is(err, ErrForbidden) is true for a ResponseError with status 403.
+type ResponseError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// The response body, which shall be of "Content-Type: text/plain" according to the LIQUID specification.
+	Message string
+}
+
+// Error implements the builtin/error interface.
+func (e ResponseError) Error() string {
+	msg := fmt.Sprintf("%s %s returned %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns one of the Err... variables in this package, depending on the status code.
+func (e ResponseError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode >= 400 && e.StatusCode < 500:
+		return ErrClientSide
+	case e.StatusCode >= 500 && e.StatusCode < 600:
+		return ErrServerSide
+	default:
+		return ErrUnexpectedStatus
+	}
+}
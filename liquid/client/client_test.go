@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquidclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+var testServiceInfo = liquid.ServiceInfo{
+	Version: 42,
+	Resources: map[liquid.ResourceName]liquid.ResourceInfo{
+		"things": {
+			Unit:        liquid.UnitNone,
+			Topology:    liquid.FlatTopology,
+			HasCapacity: true,
+			HasQuota:    true,
+		},
+	},
+}
+
+// A very small mock liquid that records the requests sent to it.
+type mockLiquid struct {
+	Responses map[string]mockResponse // key = "METHOD /path"
+	Requests  []mockRequest
+}
+
+type mockResponse struct {
+	StatusCode int
+	Body       any // if string, is sent as text/plain; otherwise as JSON
+}
+
+type mockRequest struct {
+	Method string
+	Path   string
+	Token  string
+	Body   string
+}
+
+func (m *mockLiquid) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body) //nolint:errcheck // only for testing
+	m.Requests = append(m.Requests, mockRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Token:  r.Header.Get("X-Auth-Token"),
+		Body:   string(body),
+	})
+
+	resp, ok := m.Responses[r.Method+" "+r.URL.Path]
+	if !ok {
+		http.Error(w, "no such endpoint", http.StatusNotFound)
+		return
+	}
+	switch body := resp.Body.(type) {
+	case nil:
+		w.WriteHeader(resp.StatusCode)
+	case string:
+		http.Error(w, body, resp.StatusCode)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.StatusCode)
+		_ = json.NewEncoder(w).Encode(body) //nolint:errcheck // only for testing
+	}
+}
+
+func setupTest(t *testing.T, opts Opts) (*mockLiquid, *Client) {
+	t.Helper()
+	m := &mockLiquid{Responses: make(map[string]mockResponse)}
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+
+	// test that a base URL with a subpath is supported
+	c, err := New(srv.URL+"/liquid-test/", opts)
+	assert.ErrEqual(t, err, nil)
+	return m, c
+}
+
+func TestClientHappyPath(t *testing.T) {
+	ctx := t.Context()
+	m, c := setupTest(t, Opts{
+		GetToken: func(context.Context) (string, error) { return "secret-token", nil },
+	})
+
+	m.Responses["GET /liquid-test/v1/info"] = mockResponse{200, testServiceInfo}
+	info, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, info.Version, 42)
+
+	m.Responses["POST /liquid-test/v1/report-capacity"] = mockResponse{200, liquid.ServiceCapacityReport{
+		InfoVersion: 42,
+		Resources: map[liquid.ResourceName]*liquid.ResourceCapacityReport{
+			"things": {PerAZ: liquid.InAnyAZ(liquid.AZResourceCapacityReport{Capacity: 100})},
+		},
+	}}
+	capaReport, err := c.GetCapacityReport(ctx, liquid.ServiceCapacityRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, capaReport.Resources["things"].PerAZ[liquid.AvailabilityZoneAny].Capacity, 100)
+
+	m.Responses["POST /liquid-test/v1/projects/uuid-for-dresden/report-usage"] = mockResponse{200, liquid.ServiceUsageReport{
+		InfoVersion: 42,
+		Resources: map[liquid.ResourceName]*liquid.ResourceUsageReport{
+			"things": {
+				Quota: Some[int64](10),
+				PerAZ: liquid.InAnyAZ(liquid.AZResourceUsageReport{Usage: 5}),
+			},
+		},
+	}}
+	usageReport, err := c.GetUsageReport(ctx, "uuid-for-dresden", liquid.ServiceUsageRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, usageReport.Resources["things"].Quota, Some[int64](10))
+
+	m.Responses["PUT /liquid-test/v1/projects/uuid-for-dresden/quota"] = mockResponse{204, nil}
+	err = c.PutQuota(ctx, "uuid-for-dresden", liquid.ServiceQuotaRequest{
+		Resources: map[liquid.ResourceName]liquid.ResourceQuotaRequest{"things": {Quota: 20}},
+	})
+	assert.ErrEqual(t, err, nil)
+
+	m.Responses["POST /liquid-test/v1/change-commitments"] = mockResponse{200, liquid.CommitmentChangeResponse{
+		RejectionReason: "not enough capacity",
+	}}
+	ccResponse, err := c.ChangeCommitments(ctx, liquid.CommitmentChangeRequest{AZ: "az-one", InfoVersion: 42})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, ccResponse.RejectionReason, "not enough capacity")
+
+	// check that all requests were sent as expected
+	assert.Equal(t, len(m.Requests), 5)
+	for _, req := range m.Requests {
+		assert.Equal(t, req.Token, "secret-token")
+	}
+	assert.Equal(t, m.Requests[0].Body, "")
+	assert.Equal(t, m.Requests[3].Body, `{"resources":{"things":{"quota":20}}}`)
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := t.Context()
+	m, c := setupTest(t, Opts{})
+
+	testCases := []struct {
+		StatusCode    int
+		Sentinel      error
+		ExpectedError string
+	}{
+		{401, ErrUnauthorized, "GET /liquid-test/v1/info returned 401 Unauthorized: no token given"},
+		{403, ErrForbidden, "GET /liquid-test/v1/info returned 403 Forbidden: no token given"},
+		{409, ErrClientSide, "GET /liquid-test/v1/info returned 409 Conflict: no token given"},
+		{503, ErrServerSide, "GET /liquid-test/v1/info returned 503 Service Unavailable: no token given"},
+		{302, ErrUnexpectedStatus, "GET /liquid-test/v1/info returned 302 Found: no token given"},
+	}
+	for _, tc := range testCases {
+		m.Responses["GET /liquid-test/v1/info"] = mockResponse{tc.StatusCode, "no token given"}
+		_, err := c.GetInfo(ctx)
+		assert.ErrEqual(t, err, tc.ExpectedError)
+		assert.Equal(t, errors.Is(err, tc.Sentinel), true)
+
+		var rerr ResponseError
+		assert.Equal(t, errors.As(err, &rerr), true)
+		assert.Equal(t, rerr.StatusCode, tc.StatusCode)
+		assert.Equal(t, rerr.Message, "no token given")
+	}
+
+	// PutQuota expects 204, so 200 is unexpected
+	m.Responses["PUT /liquid-test/v1/projects/uuid-for-dresden/quota"] = mockResponse{200, map[string]any{}}
+	err := c.PutQuota(ctx, "uuid-for-dresden", liquid.ServiceQuotaRequest{})
+	assert.ErrEqual(t, err, "PUT /liquid-test/v1/projects/uuid-for-dresden/quota returned 200 OK: {}")
+	assert.Equal(t, errors.Is(err, ErrUnexpectedStatus), true)
+}
+
+func TestClientValidatesResponses(t *testing.T) {
+	ctx := t.Context()
+	m, c := setupTest(t, Opts{ValidateResponses: true})
+
+	// invalid ServiceInfo is rejected
+	m.Responses["GET /liquid-test/v1/info"] = mockResponse{200, liquid.ServiceInfo{
+		Version: 41,
+		Resources: map[liquid.ResourceName]liquid.ResourceInfo{
+			"things": {Topology: "weird"},
+		},
+	}}
+	_, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, `received ServiceInfo is invalid: .Resources["things"] has invalid topology "weird"`)
+
+	// GetUsageReport() will implicitly call GetInfo() to obtain a ServiceInfo to validate against
+	m.Responses["GET /liquid-test/v1/info"] = mockResponse{200, testServiceInfo}
+	m.Responses["POST /liquid-test/v1/projects/uuid-for-dresden/report-usage"] = mockResponse{200, liquid.ServiceUsageReport{
+		InfoVersion: 42,
+		Resources: map[liquid.ResourceName]*liquid.ResourceUsageReport{
+			"things": {
+				PerAZ: liquid.InAnyAZ(liquid.AZResourceUsageReport{Usage: 5}),
+			},
+		},
+	}}
+	m.Requests = nil
+	_, err = c.GetUsageReport(ctx, "uuid-for-dresden", liquid.ServiceUsageRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, `received ServiceUsageReport is invalid: .Resources["things"] has no quota reported on resource level, which is invalid for HasQuota = true and topology "flat"`)
+	assert.Equal(t, len(m.Requests), 2)
+	assert.Equal(t, m.Requests[1].Path, "/liquid-test/v1/info")
+
+	// since the ServiceInfo is cached now, a second report will not trigger another GetInfo() call
+	m.Responses["POST /liquid-test/v1/report-capacity"] = mockResponse{200, liquid.ServiceCapacityReport{
+		InfoVersion: 42,
+		Resources: map[liquid.ResourceName]*liquid.ResourceCapacityReport{
+			"things": {PerAZ: liquid.InAnyAZ(liquid.AZResourceCapacityReport{Capacity: 100})},
+		},
+	}}
+	m.Requests = nil
+	_, err = c.GetCapacityReport(ctx, liquid.ServiceCapacityRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, len(m.Requests), 1)
+}
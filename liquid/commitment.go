@@ -159,6 +159,8 @@ func (c Commitment) Clone() Commitment {
 //	start = "confirmed"                             // commitment that takes effect right away (ConfirmBy = nil)
 //	anyNonFinal -> "expired" = final                // commitment stops taking effect after ExpiresAt
 //	anyNonFinal -> "superseded" = final             // commitment stops taking effect if replaced by other commitments
+//
+// The full list of legal transitions, including creation and deletion, can be obtained from func [CommitmentStatusTransitions].
 type CommitmentStatus string
 
 const (
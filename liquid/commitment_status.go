@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	. "go.xyrillian.de/gg/option"
+)
+
+// CommitmentStatusTransition describes a legal combination of OldStatus and NewStatus on a [Commitment].
+// The full list of legal transitions can be obtained from func [CommitmentStatusTransitions].
+type CommitmentStatusTransition struct {
+	// If None, this transition describes a commitment being created (or moved into a project or resource).
+	From Option[CommitmentStatus]
+	// If None, this transition describes a commitment being deleted (or moved away from a project or resource).
+	To Option[CommitmentStatus]
+	// A human-readable explanation of the circumstances under which this transition occurs.
+	Reason string
+}
+
+const (
+	reasonCommitmentMovedIn  = "moved in from another project or resource with the same status"
+	reasonCommitmentChanged  = "attributes other than the status have changed (e.g. ExpiresAt after a renewal)"
+	reasonCommitmentExpired  = "ExpiresAt has passed"
+	reasonCommitmentReplaced = "replaced by other commitments (e.g. when split or converted)"
+	reasonCommitmentRemoved  = "deleted, or moved away into another project or resource"
+)
+
+// This list is ordered such that rendered diagrams follow the typical lifecycle of a commitment.
+var commitmentStatusTransitions = []CommitmentStatusTransition{
+	{None[CommitmentStatus](), Some(CommitmentStatusPlanned), "created with a ConfirmBy date in the future"},
+	{None[CommitmentStatus](), Some(CommitmentStatusPending), reasonCommitmentMovedIn},
+	{None[CommitmentStatus](), Some(CommitmentStatusGuaranteed), "created with a ConfirmBy date in the future and guaranteed capacity"},
+	{None[CommitmentStatus](), Some(CommitmentStatusConfirmed), "created for immediate confirmation"},
+
+	{Some(CommitmentStatusPlanned), Some(CommitmentStatusPending), "ConfirmBy date has passed, but not enough capacity is available"},
+	{Some(CommitmentStatusPlanned), Some(CommitmentStatusConfirmed), "ConfirmBy date has passed and enough capacity is available"},
+	{Some(CommitmentStatusPending), Some(CommitmentStatusConfirmed), "confirmed as enough capacity has become available"},
+	{Some(CommitmentStatusGuaranteed), Some(CommitmentStatusConfirmed), "ConfirmBy date has passed (confirmation was guaranteed in advance)"},
+
+	{Some(CommitmentStatusPlanned), Some(CommitmentStatusPlanned), reasonCommitmentChanged},
+	{Some(CommitmentStatusPending), Some(CommitmentStatusPending), reasonCommitmentChanged},
+	{Some(CommitmentStatusGuaranteed), Some(CommitmentStatusGuaranteed), reasonCommitmentChanged},
+	{Some(CommitmentStatusConfirmed), Some(CommitmentStatusConfirmed), reasonCommitmentChanged},
+
+	{Some(CommitmentStatusPlanned), Some(CommitmentStatusExpired), reasonCommitmentExpired},
+	{Some(CommitmentStatusPending), Some(CommitmentStatusExpired), reasonCommitmentExpired},
+	{Some(CommitmentStatusGuaranteed), Some(CommitmentStatusExpired), reasonCommitmentExpired},
+	{Some(CommitmentStatusConfirmed), Some(CommitmentStatusExpired), reasonCommitmentExpired},
+
+	{Some(CommitmentStatusPlanned), Some(CommitmentStatusSuperseded), reasonCommitmentReplaced},
+	{Some(CommitmentStatusPending), Some(CommitmentStatusSuperseded), reasonCommitmentReplaced},
+	{Some(CommitmentStatusGuaranteed), Some(CommitmentStatusSuperseded), reasonCommitmentReplaced},
+	{Some(CommitmentStatusConfirmed), Some(CommitmentStatusSuperseded), reasonCommitmentReplaced},
+
+	{Some(CommitmentStatusPlanned), None[CommitmentStatus](), reasonCommitmentRemoved},
+	{Some(CommitmentStatusPending), None[CommitmentStatus](), reasonCommitmentRemoved},
+	{Some(CommitmentStatusGuaranteed), None[CommitmentStatus](), reasonCommitmentRemoved},
+	{Some(CommitmentStatusConfirmed), None[CommitmentStatus](), reasonCommitmentRemoved},
+	{Some(CommitmentStatusSuperseded), None[CommitmentStatus](), reasonCommitmentRemoved},
+	{Some(CommitmentStatusExpired), None[CommitmentStatus](), reasonCommitmentRemoved},
+}
+
+// CommitmentStatusTransitions returns the list of all legal status transitions for a [Commitment].
+// The returned slice is a copy and may be modified by the caller.
+func CommitmentStatusTransitions() []CommitmentStatusTransition {
+	return slices.Clone(commitmentStatusTransitions)
+}
+
+// FindCommitmentStatusTransition returns the entry in CommitmentStatusTransitions() that matches the given OldStatus and NewStatus.
+// If the transition is not legal, false is returned.
+func FindCommitmentStatusTransition(oldStatus, newStatus Option[CommitmentStatus]) (CommitmentStatusTransition, bool) {
+	for _, t := range commitmentStatusTransitions {
+		if t.From == oldStatus && t.To == newStatus {
+			return t, true
+		}
+	}
+	return CommitmentStatusTransition{}, false
+}
+
+// CanTransitionTo returns whether a commitment in this status may move into the given next status.
+// If next is None, this checks whether the commitment may be deleted (or moved away).
+func (s CommitmentStatus) CanTransitionTo(next Option[CommitmentStatus]) bool {
+	_, ok := FindCommitmentStatusTransition(Some(s), next)
+	return ok
+}
+
+// IsFinal returns whether no further status transitions are possible from this status.
+// A commitment in a final status can only be deleted.
+func (s CommitmentStatus) IsFinal() bool {
+	return s == CommitmentStatusSuperseded || s == CommitmentStatusExpired
+}
+
+// CommitmentStatusTransitionsAsMermaid renders CommitmentStatusTransitions() as a Mermaid state diagram.
+// Creation and deletion are shown as transitions from and to the pseudo-state "[*]".
+func CommitmentStatusTransitionsAsMermaid() string {
+	var sb strings.Builder
+	sb.WriteString("stateDiagram-v2\n")
+	for _, t := range commitmentStatusTransitions {
+		fmt.Fprintf(&sb, "    %s --> %s : %s\n",
+			t.From.UnwrapOr("[*]"), t.To.UnwrapOr("[*]"), t.Reason)
+	}
+	return sb.String()
+}
+
+// CommitmentStatusTransitionsAsGraphviz renders CommitmentStatusTransitions() as a Graphviz digraph in the DOT language.
+// Creation and deletion are shown as transitions from and to the pseudo-nodes "start" and "end", respectively.
+func CommitmentStatusTransitionsAsGraphviz() string {
+	var sb strings.Builder
+	sb.WriteString("digraph commitment_status {\n")
+	sb.WriteString("    start [shape=point];\n")
+	sb.WriteString("    end [shape=doublecircle, label=\"\", width=0.2];\n")
+	for _, t := range commitmentStatusTransitions {
+		fmt.Fprintf(&sb, "    %q -> %q [label=%q];\n",
+			t.From.UnwrapOr("start"), t.To.UnwrapOr("end"), t.Reason)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid
+
+import (
+	"strings"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+)
+
+func TestCommitmentStatusTransitionTable(t *testing.T) {
+	seen := make(map[[2]Option[CommitmentStatus]]bool)
+	for _, tr := range CommitmentStatusTransitions() {
+		key := [2]Option[CommitmentStatus]{tr.From, tr.To}
+		if seen[key] {
+			t.Errorf("duplicate transition: %#v", tr)
+		}
+		seen[key] = true
+
+		if tr.From.IsNone() && tr.To.IsNone() {
+			t.Errorf("transition from None to None: %#v", tr)
+		}
+		for _, s := range []Option[CommitmentStatus]{tr.From, tr.To} {
+			if status, ok := s.Unpack(); ok && !status.IsValid() {
+				t.Errorf("invalid status in transition: %#v", tr)
+			}
+		}
+		if from, ok := tr.From.Unpack(); ok && from.IsFinal() && tr.To.IsSome() {
+			t.Errorf("transition out of final status: %#v", tr)
+		}
+		if tr.Reason == "" {
+			t.Errorf("missing reason on transition: %#v", tr)
+		}
+	}
+}
+
+func TestCommitmentStatusCanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		From     CommitmentStatus
+		To       Option[CommitmentStatus]
+		Expected bool
+	}{
+		{CommitmentStatusPlanned, Some(CommitmentStatusPending), true},
+		{CommitmentStatusPlanned, Some(CommitmentStatusConfirmed), true},
+		{CommitmentStatusPlanned, Some(CommitmentStatusGuaranteed), false},
+		{CommitmentStatusPending, Some(CommitmentStatusConfirmed), true},
+		{CommitmentStatusPending, Some(CommitmentStatusPlanned), false},
+		{CommitmentStatusGuaranteed, Some(CommitmentStatusConfirmed), true},
+		{CommitmentStatusConfirmed, Some(CommitmentStatusConfirmed), true},
+		{CommitmentStatusConfirmed, Some(CommitmentStatusGuaranteed), false},
+		{CommitmentStatusConfirmed, Some(CommitmentStatusExpired), true},
+		{CommitmentStatusConfirmed, Some(CommitmentStatusSuperseded), true},
+		{CommitmentStatusConfirmed, None[CommitmentStatus](), true},
+		{CommitmentStatusExpired, Some(CommitmentStatusExpired), false},
+		{CommitmentStatusExpired, Some(CommitmentStatusConfirmed), false},
+		{CommitmentStatusExpired, None[CommitmentStatus](), true},
+		{CommitmentStatusSuperseded, Some(CommitmentStatusExpired), false},
+		{CommitmentStatus("weird"), None[CommitmentStatus](), false},
+	}
+	for _, tc := range testCases {
+		actual := tc.From.CanTransitionTo(tc.To)
+		if actual != tc.Expected {
+			t.Errorf("expected %q.CanTransitionTo(%v) = %t, but got %t", tc.From, tc.To, tc.Expected, actual)
+		}
+	}
+
+	tr, ok := FindCommitmentStatusTransition(None[CommitmentStatus](), Some(CommitmentStatusConfirmed))
+	assert.Equal(t, ok, true)
+	assert.Equal(t, tr.Reason, "created for immediate confirmation")
+	_, ok = FindCommitmentStatusTransition(None[CommitmentStatus](), Some(CommitmentStatusExpired))
+	assert.Equal(t, ok, false)
+}
+
+func TestCommitmentStatusTransitionDiagrams(t *testing.T) {
+	mermaid := CommitmentStatusTransitionsAsMermaid()
+	expectedPrefix := "stateDiagram-v2\n" +
+		"    [*] --> planned : created with a ConfirmBy date in the future\n" +
+		"    [*] --> pending : moved in from another project or resource with the same status\n"
+	assert.Equal(t, mermaid[:len(expectedPrefix)], expectedPrefix)
+	assert.Equal(t, strings.HasSuffix(mermaid, "    expired --> [*] : deleted, or moved away into another project or resource\n"), true)
+	assert.Equal(t, strings.Count(mermaid, "\n"), 1+len(CommitmentStatusTransitions()))
+
+	graphviz := CommitmentStatusTransitionsAsGraphviz()
+	expectedPrefix = "digraph commitment_status {\n" +
+		"    start [shape=point];\n" +
+		"    end [shape=doublecircle, label=\"\", width=0.2];\n" +
+		"    \"start\" -> \"planned\" [label=\"created with a ConfirmBy date in the future\"];\n"
+	assert.Equal(t, graphviz[:len(expectedPrefix)], expectedPrefix)
+	assert.Equal(t, strings.HasSuffix(graphviz, "    \"expired\" -> \"end\" [label=\"deleted, or moved away into another project or resource\"];\n}\n"), true)
+}
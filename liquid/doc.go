@@ -90,6 +90,7 @@
 // The documentation for an endpoint may refer to a request body being expected or a response body being generated on success.
 // In all such cases, the request or response body will be encoded as "Content-Type: application/json".
 // The structure of the payload must conform to how the referenced Go type would be serialized by the Go standard library's "encoding/json" package.
+// For liquids that are not written in Go, the payload formats are also available as JSON schemas, see [JSONSchemas] and [OpenAPIDocument].
 //
 // When producing a successful response, the status code shall be 200 (OK) unless noted otherwise.
 // When producing an error response (with a status code between 400 and 599), the liquid shall include a response body of "Content-Type: text/plain" to indicate the error.
@@ -105,6 +106,8 @@
 //   - A "metric" is a floating-point-valued measurement with an optional set of labels. A label set is a map of string keys to string values.
 //   - A "metric family" is a named set of metrics where the labelset of each metric must have the same keys, but a distinct set of values.
 //
+// Funcs [WriteOpenMetrics] and [ParseOpenMetrics] convert between LIQUID metric families and the OpenMetrics text format.
+//
 // # Endpoint: GET /v1/info
 //
 // Returns information about the OpenStack service and the resources available within it.
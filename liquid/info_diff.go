@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	. "go.xyrillian.de/gg/option"
+)
+
+// ServiceInfoChangeSeverity is an enum that classifies the impact of a [ServiceInfoChange].
+// Higher values indicate a more severe impact, so severities can be compared with the usual operators.
+type ServiceInfoChangeSeverity int
+
+const (
+	// ServiceInfoChangeCompatible indicates a change that Limes can follow without any special handling,
+	// e.g. a new resource or a changed display name.
+	ServiceInfoChangeCompatible ServiceInfoChangeSeverity = iota
+	// ServiceInfoChangeRequiresMigration indicates a change that Limes can only follow after existing data has been converted,
+	// e.g. a change of topology or a unit change that requires existing values to be rescaled.
+	ServiceInfoChangeRequiresMigration
+	// ServiceInfoChangeBreaking indicates a change that cannot be followed without loss of data,
+	// e.g. a removed resource or a unit change to a different base unit.
+	ServiceInfoChangeBreaking
+)
+
+// String implements the fmt.Stringer interface.
+func (s ServiceInfoChangeSeverity) String() string {
+	switch s {
+	case ServiceInfoChangeCompatible:
+		return "compatible"
+	case ServiceInfoChangeRequiresMigration:
+		return "requires-migration"
+	case ServiceInfoChangeBreaking:
+		return "breaking"
+	default:
+		return fmt.Sprintf("ServiceInfoChangeSeverity(%d)", int(s))
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s ServiceInfoChangeSeverity) MarshalText() ([]byte, error) {
+	switch s {
+	case ServiceInfoChangeCompatible, ServiceInfoChangeRequiresMigration, ServiceInfoChangeBreaking:
+		return []byte(s.String()), nil
+	default:
+		return nil, fmt.Errorf("cannot marshal invalid ServiceInfoChangeSeverity %d", int(s))
+	}
+}
+
+// ServiceInfoChange describes a single difference between two versions of a [ServiceInfo].
+// Instances are returned by func [DiffServiceInfo].
+type ServiceInfoChange struct {
+	// The location of the change within the ServiceInfo, e.g. `.Resources["capacity"].Unit`.
+	Path string `json:"path"`
+	// A human-readable description of the change, e.g. `changed from "MiB" to "GiB"`.
+	Description string `json:"description"`
+	// How much impact this change has on the data that Limes stores for this service.
+	Severity ServiceInfoChangeSeverity `json:"severity"`
+}
+
+// String implements the fmt.Stringer interface.
+func (c ServiceInfoChange) String() string {
+	return fmt.Sprintf("%s %s (%s)", c.Path, c.Description, c.Severity)
+}
+
+// DiffServiceInfo compares two versions of a ServiceInfo and returns a list of all changes, sorted by path.
+// The Version fields are not compared.
+//
+// The following changes are classified as ServiceInfoChangeBreaking:
+//   - Removal of a resource or rate.
+//   - Change of the unit of a resource or rate to a unit with a different base unit (e.g. from "MiB" to "piece").
+//   - Change of the type of a metric family.
+//
+// The following changes are classified as ServiceInfoChangeRequiresMigration:
+//   - Change of the unit of a resource or rate to a different multiple of the same base unit (e.g. from "MiB" to "GiB").
+//   - Change of the topology of a resource or rate.
+//   - Change of HasQuota or HandlesCommitments on a resource.
+//   - Change of HasCapacity on a resource or HasUsage on a rate from true to false.
+//   - Removal of a metric family, or change of the label keys of a metric family.
+//
+// All other changes (including additions of resources, rates or metric families) are classified as ServiceInfoChangeCompatible.
+func DiffServiceInfo(oldInfo, newInfo ServiceInfo) []ServiceInfoChange {
+	var d serviceInfoDiffer
+	d.diffValue(".DisplayName", oldInfo.DisplayName, newInfo.DisplayName, ServiceInfoChangeCompatible)
+	diffMap(&d, ".Categories", "category", oldInfo.Categories, newInfo.Categories, ServiceInfoChangeCompatible,
+		func(path string, o, n CategoryInfo) {
+			d.diffValue(path+".DisplayName", o.DisplayName, n.DisplayName, ServiceInfoChangeCompatible)
+		})
+	diffMap(&d, ".Resources", "resource", oldInfo.Resources, newInfo.Resources, ServiceInfoChangeBreaking, d.diffResource)
+	diffMap(&d, ".Rates", "rate", oldInfo.Rates, newInfo.Rates, ServiceInfoChangeBreaking, d.diffRate)
+	diffMap(&d, ".CapacityMetricFamilies", "metric family", oldInfo.CapacityMetricFamilies, newInfo.CapacityMetricFamilies,
+		ServiceInfoChangeRequiresMigration, d.diffMetricFamily)
+	diffMap(&d, ".UsageMetricFamilies", "metric family", oldInfo.UsageMetricFamilies, newInfo.UsageMetricFamilies,
+		ServiceInfoChangeRequiresMigration, d.diffMetricFamily)
+	d.diffValue(".UsageReportNeedsProjectMetadata", oldInfo.UsageReportNeedsProjectMetadata, newInfo.UsageReportNeedsProjectMetadata, ServiceInfoChangeCompatible)
+	d.diffValue(".QuotaUpdateNeedsProjectMetadata", oldInfo.QuotaUpdateNeedsProjectMetadata, newInfo.QuotaUpdateNeedsProjectMetadata, ServiceInfoChangeCompatible)
+	d.diffValue(".CommitmentHandlingNeedsProjectMetadata", oldInfo.CommitmentHandlingNeedsProjectMetadata, newInfo.CommitmentHandlingNeedsProjectMetadata, ServiceInfoChangeCompatible)
+
+	slices.SortStableFunc(d.Changes, func(lhs, rhs ServiceInfoChange) int {
+		return strings.Compare(lhs.Path, rhs.Path)
+	})
+	return d.Changes
+}
+
+type serviceInfoDiffer struct {
+	Changes []ServiceInfoChange
+}
+
+func (d *serviceInfoDiffer) add(path string, severity ServiceInfoChangeSeverity, format string, args ...any) {
+	d.Changes = append(d.Changes, ServiceInfoChange{
+		Path:        path,
+		Description: fmt.Sprintf(format, args...),
+		Severity:    severity,
+	})
+}
+
+func (d *serviceInfoDiffer) diffValue(path string, oldValue, newValue any, severity ServiceInfoChangeSeverity) {
+	if oldValue != newValue {
+		d.add(path, severity, "changed from %#v to %#v", oldValue, newValue)
+	}
+}
+
+// diffFlag is like diffValue, but only a change from true to false has the given severity.
+func (d *serviceInfoDiffer) diffFlag(path string, oldValue, newValue bool, severityOnDisable ServiceInfoChangeSeverity) {
+	if oldValue && !newValue {
+		d.add(path, severityOnDisable, "changed from true to false")
+	}
+	if !oldValue && newValue {
+		d.add(path, ServiceInfoChangeCompatible, "changed from false to true")
+	}
+}
+
+func (d *serviceInfoDiffer) diffCategory(path string, oldCategory, newCategory Option[CategoryName]) {
+	if oldCategory != newCategory {
+		d.add(path, ServiceInfoChangeCompatible, "changed from %s to %s",
+			formatCategoryName(oldCategory), formatCategoryName(newCategory))
+	}
+}
+
+func formatCategoryName(category Option[CategoryName]) string {
+	if c, ok := category.Unpack(); ok {
+		return fmt.Sprintf("%q", c)
+	}
+	return "None"
+}
+
+func (d *serviceInfoDiffer) diffUnit(path string, oldUnit, newUnit Unit) {
+	if oldUnit == newUnit {
+		return
+	}
+	// UnitNone and UnitPiece have the same meaning within Limes
+	normalize := func(u Unit) Unit {
+		if u.IsEquivalentTo(UnitNone) {
+			return UnitPiece
+		}
+		return u
+	}
+	// units that only differ in formatting (e.g. "1000 B" and "kB") do not require values to be converted
+	if normalize(oldUnit).IsEquivalentTo(normalize(newUnit)) {
+		d.add(path, ServiceInfoChangeCompatible, "changed from %q to %q (equivalent)", oldUnit, newUnit)
+		return
+	}
+
+	oldBase, _ := normalize(oldUnit).Base()
+	newBase, _ := normalize(newUnit).Base()
+	if oldBase == newBase {
+		d.add(path, ServiceInfoChangeRequiresMigration, "changed from %q to %q (existing values need to be converted)", oldUnit, newUnit)
+	} else {
+		d.add(path, ServiceInfoChangeBreaking, "changed from %q to %q (incompatible base units)", oldUnit, newUnit)
+	}
+}
+
+func (d *serviceInfoDiffer) diffResource(path string, o, n ResourceInfo) {
+	d.diffValue(path+".DisplayName", o.DisplayName, n.DisplayName, ServiceInfoChangeCompatible)
+	d.diffCategory(path+".Category", o.Category, n.Category)
+	d.diffUnit(path+".Unit", o.Unit, n.Unit)
+	d.diffValue(path+".Topology", o.Topology, n.Topology, ServiceInfoChangeRequiresMigration)
+	d.diffFlag(path+".HasCapacity", o.HasCapacity, n.HasCapacity, ServiceInfoChangeRequiresMigration)
+	d.diffValue(path+".NeedsResourceDemand", o.NeedsResourceDemand, n.NeedsResourceDemand, ServiceInfoChangeCompatible)
+	d.diffValue(path+".HasQuota", o.HasQuota, n.HasQuota, ServiceInfoChangeRequiresMigration)
+	d.diffValue(path+".HandlesCommitments", o.HandlesCommitments, n.HandlesCommitments, ServiceInfoChangeRequiresMigration)
+	if !bytes.Equal(o.Attributes, n.Attributes) {
+		d.add(path+".Attributes", ServiceInfoChangeCompatible, "changed from %s to %s", formatAttributes(o.Attributes), formatAttributes(n.Attributes))
+	}
+}
+
+func formatAttributes(attrs []byte) string {
+	if len(attrs) == 0 {
+		return "nothing"
+	}
+	return string(attrs)
+}
+
+func (d *serviceInfoDiffer) diffRate(path string, o, n RateInfo) {
+	d.diffValue(path+".DisplayName", o.DisplayName, n.DisplayName, ServiceInfoChangeCompatible)
+	d.diffCategory(path+".Category", o.Category, n.Category)
+	d.diffUnit(path+".Unit", o.Unit, n.Unit)
+	d.diffValue(path+".Topology", o.Topology, n.Topology, ServiceInfoChangeRequiresMigration)
+	d.diffFlag(path+".HasUsage", o.HasUsage, n.HasUsage, ServiceInfoChangeRequiresMigration)
+}
+
+func (d *serviceInfoDiffer) diffMetricFamily(path string, o, n MetricFamilyInfo) {
+	d.diffValue(path+".Type", o.Type, n.Type, ServiceInfoChangeBreaking)
+	d.diffValue(path+".Help", o.Help, n.Help, ServiceInfoChangeCompatible)
+	if !slices.Equal(o.LabelKeys, n.LabelKeys) {
+		d.add(path+".LabelKeys", ServiceInfoChangeRequiresMigration, "changed from %q to %q", o.LabelKeys, n.LabelKeys)
+	}
+}
+
+func diffMap[K ~string, V any](d *serviceInfoDiffer, path, noun string, oldMap, newMap map[K]V, severityOnRemove ServiceInfoChangeSeverity, diffEntry func(string, V, V)) {
+	for _, key := range slices.Sorted(maps.Keys(oldMap)) {
+		entryPath := fmt.Sprintf("%s[%q]", path, key)
+		newValue, exists := newMap[key]
+		if exists {
+			diffEntry(entryPath, oldMap[key], newValue)
+		} else {
+			d.add(entryPath, severityOnRemove, "%s was removed", noun)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(newMap)) {
+		if _, exists := oldMap[key]; !exists {
+			d.add(fmt.Sprintf("%s[%q]", path, key), ServiceInfoChangeCompatible, "%s was added", noun)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+)
+
+func TestDiffServiceInfo(t *testing.T) {
+	// no changes
+	assert.DeepEqual(t, "changes", DiffServiceInfo(serviceInfo, serviceInfo.Clone()), []ServiceInfoChange(nil))
+
+	thousandBytes, err := UnitBytes.MultiplyBy(1000)
+	assert.ErrEqual(t, err, nil)
+	oldInfo := ServiceInfo{
+		Version:    1,
+		Categories: map[CategoryName]CategoryInfo{"base": {DisplayName: "Base"}},
+		Resources: map[ResourceName]ResourceInfo{
+			"cores":     {Unit: UnitNone, Topology: FlatTopology, HasCapacity: true, HasQuota: true},
+			"ram":       {Unit: UnitMebibytes, Topology: FlatTopology, HasCapacity: true, HasQuota: true},
+			"disk":      {Unit: UnitGibibytes, Topology: AZAwareTopology, HasQuota: true},
+			"instances": {Unit: UnitNone, Topology: AZAwareTopology, Category: Some(CategoryName("base"))},
+			"removed":   {Unit: UnitNone, Topology: FlatTopology},
+		},
+		Rates: map[RateName]RateInfo{
+			"transfer": {Unit: UnitBytes, Topology: FlatTopology, HasUsage: true},
+			"upload":   {Unit: thousandBytes, Topology: FlatTopology},
+		},
+		UsageMetricFamilies: map[MetricName]MetricFamilyInfo{
+			"servers": {Type: MetricTypeGauge, Help: "Servers.", LabelKeys: []string{"state"}},
+			"uptime":  {Type: MetricTypeGauge, LabelKeys: []string{}},
+		},
+	}
+	newInfo := ServiceInfo{
+		Version: 2,
+		Resources: map[ResourceName]ResourceInfo{
+			"cores":     {Unit: UnitPiece, Topology: AZAwareTopology, HasCapacity: true, HasQuota: true, HandlesCommitments: true},
+			"ram":       {Unit: UnitGibibytes, Topology: FlatTopology, HasCapacity: false, HasQuota: true},
+			"disk":      {Unit: UnitPiece, Topology: AZAwareTopology, HasCapacity: true, HasQuota: true},
+			"instances": {Unit: UnitNone, Topology: AZAwareTopology, Attributes: json.RawMessage(`{"x":1}`)},
+			"added":     {Unit: UnitNone, Topology: FlatTopology},
+		},
+		Rates: map[RateName]RateInfo{
+			"transfer": {Unit: UnitBytes, Topology: FlatTopology, HasUsage: false, DisplayName: "Transfer"},
+			"upload":   {Unit: UnitKilobytes, Topology: FlatTopology},
+		},
+		UsageMetricFamilies: map[MetricName]MetricFamilyInfo{
+			"servers": {Type: MetricTypeCounter, Help: "Servers.", LabelKeys: []string{"state", "flavor"}},
+		},
+		UsageReportNeedsProjectMetadata: true,
+	}
+
+	changes := DiffServiceInfo(oldInfo, newInfo)
+	actual := make([]string, len(changes))
+	for idx, change := range changes {
+		actual[idx] = change.String()
+	}
+	assert.DeepEqual(t, "changes", actual, []string{
+		`.Categories["base"] category was removed (compatible)`,
+		`.Rates["transfer"].DisplayName changed from "" to "Transfer" (compatible)`,
+		`.Rates["transfer"].HasUsage changed from true to false (requires-migration)`,
+		`.Rates["upload"].Unit changed from "1000 B" to "kB" (equivalent) (compatible)`,
+		`.Resources["added"] resource was added (compatible)`,
+		`.Resources["cores"].HandlesCommitments changed from false to true (requires-migration)`,
+		`.Resources["cores"].Topology changed from "flat" to "az-aware" (requires-migration)`,
+		`.Resources["cores"].Unit changed from "" to "piece" (equivalent) (compatible)`,
+		`.Resources["disk"].HasCapacity changed from false to true (compatible)`,
+		`.Resources["disk"].Unit changed from "GiB" to "piece" (incompatible base units) (breaking)`,
+		`.Resources["instances"].Attributes changed from nothing to {"x":1} (compatible)`,
+		`.Resources["instances"].Category changed from "base" to None (compatible)`,
+		`.Resources["ram"].HasCapacity changed from true to false (requires-migration)`,
+		`.Resources["ram"].Unit changed from "MiB" to "GiB" (existing values need to be converted) (requires-migration)`,
+		`.Resources["removed"] resource was removed (breaking)`,
+		`.UsageMetricFamilies["servers"].LabelKeys changed from ["state"] to ["state" "flavor"] (requires-migration)`,
+		`.UsageMetricFamilies["servers"].Type changed from "gauge" to "counter" (breaking)`,
+		`.UsageMetricFamilies["uptime"] metric family was removed (requires-migration)`,
+		`.UsageReportNeedsProjectMetadata changed from false to true (compatible)`,
+	})
+
+	buf, err := json.Marshal(changes[len(changes)-3])
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, string(buf), `{"path":".UsageMetricFamilies[\"servers\"].Type","description":"changed from \"gauge\" to \"counter\"","severity":"breaking"}`)
+}
@@ -0,0 +1,369 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package liquidtest contains an in-memory fake liquid for use in tests of LIQUID clients like Limes.
+//
+// The fake liquid is built on [liquidserver.Handler], so requests and reports are validated in the same way as in a real liquid.
+// Its behavior is controlled entirely by the test through the methods of [FakeLiquid]:
+//
+//	fake, srv := liquidtest.NewServer(t, info)
+//	fake.SetCapacityReport(capacityReport)
+//	fake.SetUsageReport(projectUUID, usageReport)
+//	fake.QueueFailure(liquidtest.EndpointReportCapacity, http.StatusServiceUnavailable, "try again later")
+//
+//	// ...run the code under test against srv.URL...
+//
+//	for _, req := range fake.Requests() {
+//		// ...make assertions...
+//	}
+package liquidtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/go-api-declarations/liquid"
+	liquidserver "github.com/sapcc/go-api-declarations/liquid/server"
+)
+
+// Endpoint identifies one of the endpoints from the LIQUID specification.
+// The values are the request patterns used by the fake liquid to route requests.
+type Endpoint string
+
+const (
+	EndpointInfo              Endpoint = "GET /v1/info"
+	EndpointReportCapacity    Endpoint = "POST /v1/report-capacity"
+	EndpointReportUsage       Endpoint = "POST /v1/projects/{uuid}/report-usage"
+	EndpointSetQuota          Endpoint = "PUT /v1/projects/{uuid}/quota"
+	EndpointChangeCommitments Endpoint = "POST /v1/change-commitments"
+)
+
+var allEndpoints = []Endpoint{
+	EndpointInfo,
+	EndpointReportCapacity,
+	EndpointReportUsage,
+	EndpointSetQuota,
+	EndpointChangeCommitments,
+}
+
+// Request is a request that was received by a [FakeLiquid].
+type Request struct {
+	Endpoint Endpoint
+	// Only filled for EndpointReportUsage and EndpointSetQuota.
+	ProjectUUID liquid.ProjectUUID
+	Header      http.Header
+	// The request body as sent by the client (usually a JSON document).
+	Body []byte
+}
+
+// Failure is an error response that a [FakeLiquid] produces instead of the regular response.
+// Instances are scripted through [FakeLiquid.QueueFailure].
+type Failure struct {
+	StatusCode int
+	Message    string
+}
+
+// FakeLiquid is an in-memory implementation of a liquid.
+// Instances must be created through [New] or [NewServer].
+// All methods are safe for concurrent use.
+type FakeLiquid struct {
+	handler *liquidserver.Handler
+	mux     *http.ServeMux
+
+	mutex               sync.Mutex
+	info                liquid.ServiceInfo
+	capacityReport      Option[liquid.ServiceCapacityReport]
+	usageReports        map[liquid.ProjectUUID]liquid.ServiceUsageReport
+	requests            []Request
+	failures            map[Endpoint][]Failure
+	latencies           map[Endpoint]time.Duration
+	commitmentResponses []liquid.CommitmentChangeResponse
+}
+
+// New builds a FakeLiquid with the given ServiceInfo.
+// An error is returned if the ServiceInfo is not valid.
+//
+// Initially, no capacity report and no usage reports are configured.
+// Until they are configured with SetCapacityReport and SetUsageReport, requests for these reports will fail.
+func New(ctx context.Context, info liquid.ServiceInfo) (*FakeLiquid, error) {
+	f := &FakeLiquid{
+		info:         info.Clone(),
+		usageReports: make(map[liquid.ProjectUUID]liquid.ServiceUsageReport),
+		failures:     make(map[Endpoint][]Failure),
+		latencies:    make(map[Endpoint]time.Duration),
+	}
+	handler, err := liquidserver.NewHandler(ctx, fakeLogic{f}, liquidserver.Opts{})
+	if err != nil {
+		return nil, err
+	}
+	f.handler = handler
+
+	f.mux = http.NewServeMux()
+	for _, endpoint := range allEndpoints {
+		f.mux.Handle(string(endpoint), f.wrap(endpoint))
+	}
+	f.mux.Handle("/", handler) // unknown paths and methods are rejected by the handler in the usual way
+	return f, nil
+}
+
+// NewServer builds a FakeLiquid and serves it on an httptest.Server.
+// The server is shut down automatically when the test finishes.
+// If the ServiceInfo is not valid, the test fails immediately.
+func NewServer(t testing.TB, info liquid.ServiceInfo) (*FakeLiquid, *httptest.Server) {
+	t.Helper()
+	f, err := New(t.Context(), info)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (f *FakeLiquid) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mux.ServeHTTP(w, r)
+}
+
+func (f *FakeLiquid) wrap(endpoint Endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "cannot read request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		f.mutex.Lock()
+		f.requests = append(f.requests, Request{
+			Endpoint:    endpoint,
+			ProjectUUID: liquid.ProjectUUID(r.PathValue("uuid")),
+			Header:      r.Header.Clone(),
+			Body:        body,
+		})
+		latency := f.latencies[endpoint]
+		var failure Option[Failure]
+		if queue := f.failures[endpoint]; len(queue) > 0 {
+			failure = Some(queue[0])
+			f.failures[endpoint] = queue[1:]
+		}
+		f.mutex.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if failure, ok := failure.Unpack(); ok {
+			http.Error(w, failure.Message, failure.StatusCode)
+			return
+		}
+		f.handler.ServeHTTP(w, r)
+	})
+}
+
+// SetServiceInfo replaces the ServiceInfo of this liquid.
+// An error is returned if the ServiceInfo is not valid.
+//
+// Note that the InfoVersion fields in all reports are filled automatically.
+// Configured reports are not adjusted in any other way, so they may need to be replaced to match the new ServiceInfo.
+func (f *FakeLiquid) SetServiceInfo(ctx context.Context, info liquid.ServiceInfo) error {
+	f.mutex.Lock()
+	previousInfo := f.info
+	f.info = info.Clone()
+	f.mutex.Unlock()
+
+	err := f.handler.ReloadServiceInfo(ctx)
+	if err != nil {
+		f.mutex.Lock()
+		f.info = previousInfo
+		f.mutex.Unlock()
+	}
+	return err
+}
+
+// SetCapacityReport sets the response for POST /v1/report-capacity.
+func (f *FakeLiquid) SetCapacityReport(report liquid.ServiceCapacityReport) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.capacityReport = Some(report.Clone())
+}
+
+// SetUsageReport sets the response for POST /v1/projects/:uuid/report-usage for the given project.
+// Until this is called for a project, requests regarding this project will fail with status 404 (Not Found).
+//
+// Quota values in the report will be overwritten when quota is set for this project through PUT /v1/projects/:uuid/quota.
+func (f *FakeLiquid) SetUsageReport(projectUUID liquid.ProjectUUID, report liquid.ServiceUsageReport) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.usageReports[projectUUID] = report.Clone()
+}
+
+// UsageReport returns a copy of the current usage report for the given project, including all quota updates received so far.
+// If no usage report was configured for this project, false is returned.
+func (f *FakeLiquid) UsageReport(projectUUID liquid.ProjectUUID) (liquid.ServiceUsageReport, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	report, exists := f.usageReports[projectUUID]
+	if !exists {
+		return liquid.ServiceUsageReport{}, false
+	}
+	return report.Clone(), true
+}
+
+// QueueFailure arranges for the next request to the given endpoint to fail with the given status code and error message.
+// If multiple failures are queued for the same endpoint, they are used for consecutive requests in the order in which they were queued.
+// Failing requests are still recorded, but do not have any effect on the state of the liquid.
+func (f *FakeLiquid) QueueFailure(endpoint Endpoint, statusCode int, message string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failures[endpoint] = append(f.failures[endpoint], Failure{StatusCode: statusCode, Message: message})
+}
+
+// SetLatency arranges for all subsequent requests to the given endpoint to be delayed by the given duration before a response is produced.
+// If the request is cancelled while waiting, no response is produced.
+// Set a latency of 0 to remove the delay.
+func (f *FakeLiquid) SetLatency(endpoint Endpoint, latency time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.latencies[endpoint] = latency
+}
+
+// QueueCommitmentChangeResponse sets the response for the next request to POST /v1/change-commitments (including dry runs).
+// If multiple responses are queued, they are used for consecutive requests in the order in which they were queued.
+// If no response is queued, all commitment changes are accepted.
+func (f *FakeLiquid) QueueCommitmentChangeResponse(resp liquid.CommitmentChangeResponse) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.commitmentResponses = append(f.commitmentResponses, resp)
+}
+
+// QueueCommitmentChangeRejection is a shorthand for QueueCommitmentChangeResponse with the given RejectionReason and RetryAt.
+func (f *FakeLiquid) QueueCommitmentChangeRejection(reason string, retryAt Option[time.Time]) {
+	f.QueueCommitmentChangeResponse(liquid.CommitmentChangeResponse{RejectionReason: reason, RetryAt: retryAt})
+}
+
+// Requests returns all requests that were received so far, in the order in which they were received.
+func (f *FakeLiquid) Requests() []Request {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	result := make([]Request, len(f.requests))
+	for idx, req := range f.requests {
+		result[idx] = Request{
+			Endpoint:    req.Endpoint,
+			ProjectUUID: req.ProjectUUID,
+			Header:      req.Header.Clone(),
+			Body:        bytes.Clone(req.Body),
+		}
+	}
+	return result
+}
+
+// RequestsTo is like Requests, but only returns requests to the given endpoint.
+func (f *FakeLiquid) RequestsTo(endpoint Endpoint) []Request {
+	var result []Request
+	for _, req := range f.Requests() {
+		if req.Endpoint == endpoint {
+			result = append(result, req)
+		}
+	}
+	return result
+}
+
+// ClearRequests forgets about all requests that were received so far.
+func (f *FakeLiquid) ClearRequests() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.requests = nil
+}
+
+// fakeLogic implements liquidserver.Logic for FakeLiquid.
+// This is a separate type to avoid having these methods in the public API of FakeLiquid.
+type fakeLogic struct {
+	f *FakeLiquid
+}
+
+var errNoSuchProject = liquidserver.HTTPError{StatusCode: http.StatusNotFound, Message: "no usage report configured for this project"}
+
+// BuildServiceInfo implements the liquidserver.Logic interface.
+func (l fakeLogic) BuildServiceInfo(ctx context.Context) (liquid.ServiceInfo, error) {
+	l.f.mutex.Lock()
+	defer l.f.mutex.Unlock()
+	return l.f.info.Clone(), nil
+}
+
+// ScanCapacity implements the liquidserver.Logic interface.
+func (l fakeLogic) ScanCapacity(ctx context.Context, req liquid.ServiceCapacityRequest, info liquid.ServiceInfo) (liquid.ServiceCapacityReport, error) {
+	l.f.mutex.Lock()
+	defer l.f.mutex.Unlock()
+	report, ok := l.f.capacityReport.Unpack()
+	if !ok {
+		return liquid.ServiceCapacityReport{}, errors.New("no capacity report configured")
+	}
+	report = report.Clone()
+	report.InfoVersion = info.Version
+	return report, nil
+}
+
+// ScanUsage implements the liquidserver.Logic interface.
+func (l fakeLogic) ScanUsage(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceUsageRequest, info liquid.ServiceInfo) (liquid.ServiceUsageReport, error) {
+	l.f.mutex.Lock()
+	defer l.f.mutex.Unlock()
+	report, exists := l.f.usageReports[projectUUID]
+	if !exists {
+		return liquid.ServiceUsageReport{}, errNoSuchProject
+	}
+	report = report.Clone()
+	report.InfoVersion = info.Version
+	return report, nil
+}
+
+// SetQuota implements the liquidserver.Logic interface.
+func (l fakeLogic) SetQuota(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceQuotaRequest, info liquid.ServiceInfo) error {
+	l.f.mutex.Lock()
+	defer l.f.mutex.Unlock()
+	report, exists := l.f.usageReports[projectUUID]
+	if !exists {
+		return errNoSuchProject
+	}
+
+	for resName, resQuota := range req.Resources {
+		resReport := report.Resources[resName]
+		if resReport == nil {
+			continue // the usage report will be rejected by validation anyway
+		}
+		if info.Resources[resName].Topology != liquid.AZSeparatedTopology {
+			resReport.Quota = Some(int64(resQuota.Quota)) //nolint:gosec // uint64 -> int64 overflow is not a concern in test fixtures
+			continue
+		}
+		for az, azQuota := range resQuota.PerAZ {
+			if azReport := resReport.PerAZ[az]; azReport != nil {
+				azReport.Quota = Some(int64(azQuota.Quota)) //nolint:gosec // uint64 -> int64 overflow is not a concern in test fixtures
+			}
+		}
+	}
+	return nil
+}
+
+// ReviewCommitmentChange implements the liquidserver.Logic interface.
+func (l fakeLogic) ReviewCommitmentChange(ctx context.Context, req liquid.CommitmentChangeRequest, info liquid.ServiceInfo) (liquid.CommitmentChangeResponse, error) {
+	l.f.mutex.Lock()
+	defer l.f.mutex.Unlock()
+	if len(l.f.commitmentResponses) == 0 {
+		return liquid.CommitmentChangeResponse{}, nil
+	}
+	resp := l.f.commitmentResponses[0]
+	l.f.commitmentResponses = l.f.commitmentResponses[1:]
+	return resp, nil
+}
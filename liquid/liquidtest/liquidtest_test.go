@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquidtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/go-api-declarations/liquid"
+	liquidclient "github.com/sapcc/go-api-declarations/liquid/client"
+)
+
+const projectUUID = "d41d8cd98f00b204e9800998ecf8427e"
+
+var testServiceInfo = liquid.ServiceInfo{
+	Version: 1,
+	Resources: map[liquid.ResourceName]liquid.ResourceInfo{
+		"things": {
+			Unit:               liquid.UnitNone,
+			Topology:           liquid.FlatTopology,
+			HasCapacity:        true,
+			HasQuota:           true,
+			HandlesCommitments: true,
+		},
+		"zoned_things": {
+			Unit:     liquid.UnitNone,
+			Topology: liquid.AZSeparatedTopology,
+			HasQuota: true,
+		},
+	},
+}
+
+var testUsageReport = liquid.ServiceUsageReport{
+	Resources: map[liquid.ResourceName]*liquid.ResourceUsageReport{
+		"things": {
+			Quota: Some[int64](10),
+			PerAZ: liquid.InAnyAZ(liquid.AZResourceUsageReport{Usage: 5}),
+		},
+		"zoned_things": {
+			PerAZ: map[liquid.AvailabilityZone]*liquid.AZResourceUsageReport{
+				"az-one": {Usage: 1, Quota: Some[int64](2)},
+			},
+		},
+	},
+}
+
+func setupTest(t *testing.T) (*FakeLiquid, *liquidclient.Client) {
+	t.Helper()
+	fake, srv := NewServer(t, testServiceInfo)
+	c, err := liquidclient.New(srv.URL, liquidclient.Opts{
+		GetToken: func(context.Context) (string, error) { return "secret-token", nil },
+	})
+	assert.ErrEqual(t, err, nil)
+	return fake, c
+}
+
+func TestFakeLiquidReportsAndQuota(t *testing.T) {
+	ctx := t.Context()
+	fake, c := setupTest(t)
+	usageReq := liquid.ServiceUsageRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}}
+
+	// reports are only available once configured
+	_, err := c.GetCapacityReport(ctx, liquid.ServiceCapacityRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, "POST /v1/report-capacity returned 500 Internal Server Error: no capacity report configured")
+	_, err = c.GetUsageReport(ctx, projectUUID, usageReq)
+	assert.ErrEqual(t, err, "POST /v1/projects/"+projectUUID+"/report-usage returned 404 Not Found: no usage report configured for this project")
+
+	fake.SetCapacityReport(liquid.ServiceCapacityReport{
+		Resources: map[liquid.ResourceName]*liquid.ResourceCapacityReport{
+			"things": {PerAZ: liquid.InAnyAZ(liquid.AZResourceCapacityReport{Capacity: 100})},
+		},
+	})
+	capaReport, err := c.GetCapacityReport(ctx, liquid.ServiceCapacityRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, capaReport.InfoVersion, 1)
+	assert.Equal(t, capaReport.Resources["things"].PerAZ[liquid.AvailabilityZoneAny].Capacity, 100)
+
+	fake.SetUsageReport(projectUUID, testUsageReport)
+	usageReport, err := c.GetUsageReport(ctx, projectUUID, usageReq)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, usageReport.Resources["things"].Quota, Some[int64](10))
+
+	// quota updates are applied to the usage report
+	err = c.PutQuota(ctx, projectUUID, liquid.ServiceQuotaRequest{
+		Resources: map[liquid.ResourceName]liquid.ResourceQuotaRequest{
+			"things": {Quota: 20},
+			"zoned_things": {
+				Quota: 7,
+				PerAZ: map[liquid.AvailabilityZone]liquid.AZResourceQuotaRequest{"az-one": {Quota: 7}},
+			},
+		},
+	})
+	assert.ErrEqual(t, err, nil)
+	usageReport, err = c.GetUsageReport(ctx, projectUUID, usageReq)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, usageReport.Resources["things"].Quota, Some[int64](20))
+	assert.Equal(t, usageReport.Resources["zoned_things"].Quota, None[int64]())
+	assert.Equal(t, usageReport.Resources["zoned_things"].PerAZ["az-one"].Quota, Some[int64](7))
+
+	storedReport, exists := fake.UsageReport(projectUUID)
+	assert.Equal(t, exists, true)
+	assert.Equal(t, storedReport.Resources["things"].Quota, Some[int64](20))
+
+	// the original fixture was not modified
+	assert.Equal(t, testUsageReport.Resources["things"].Quota, Some[int64](10))
+}
+
+func TestFakeLiquidRecordsRequests(t *testing.T) {
+	ctx := t.Context()
+	fake, c := setupTest(t)
+	fake.SetUsageReport(projectUUID, testUsageReport)
+
+	_, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, nil)
+	err = c.PutQuota(ctx, projectUUID, liquid.ServiceQuotaRequest{
+		Resources: map[liquid.ResourceName]liquid.ResourceQuotaRequest{"things": {Quota: 20}},
+	})
+	assert.ErrEqual(t, err, nil)
+
+	requests := fake.Requests()
+	assert.Equal(t, len(requests), 2)
+	assert.Equal(t, requests[0].Endpoint, EndpointInfo)
+	assert.Equal(t, requests[0].ProjectUUID, "")
+	assert.Equal(t, requests[0].Header.Get("X-Auth-Token"), "secret-token")
+	assert.Equal(t, requests[1].Endpoint, EndpointSetQuota)
+	assert.Equal(t, requests[1].ProjectUUID, projectUUID)
+
+	var quotaReq liquid.ServiceQuotaRequest
+	err = json.Unmarshal(requests[1].Body, &quotaReq)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, quotaReq.Resources["things"].Quota, 20)
+
+	assert.Equal(t, len(fake.RequestsTo(EndpointSetQuota)), 1)
+	assert.Equal(t, len(fake.RequestsTo(EndpointReportUsage)), 0)
+	fake.ClearRequests()
+	assert.Equal(t, len(fake.Requests()), 0)
+}
+
+func TestFakeLiquidScriptedBehavior(t *testing.T) {
+	ctx := t.Context()
+	fake, c := setupTest(t)
+
+	// failures are used up one at a time
+	fake.QueueFailure(EndpointInfo, http.StatusServiceUnavailable, "try again later")
+	fake.QueueFailure(EndpointInfo, http.StatusUnauthorized, "who are you?")
+	_, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, "GET /v1/info returned 503 Service Unavailable: try again later")
+	assert.Equal(t, errors.Is(err, liquidclient.ErrServerSide), true)
+	_, err = c.GetInfo(ctx)
+	assert.ErrEqual(t, err, "GET /v1/info returned 401 Unauthorized: who are you?")
+	_, err = c.GetInfo(ctx)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, len(fake.RequestsTo(EndpointInfo)), 3)
+
+	// latency is applied until the request is cancelled
+	fake.SetLatency(EndpointInfo, time.Minute)
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = c.GetInfo(shortCtx)
+	assert.Equal(t, errors.Is(err, context.DeadlineExceeded), true)
+	fake.SetLatency(EndpointInfo, 0)
+
+	// commitment changes are accepted unless a rejection is queued
+	req := liquid.CommitmentChangeRequest{AZ: "az-one", InfoVersion: 1}
+	resp, err := c.ChangeCommitments(ctx, req)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, resp, liquid.CommitmentChangeResponse{})
+
+	retryAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake.QueueCommitmentChangeRejection("not enough capacity", Some(retryAt))
+	resp, err = c.ChangeCommitments(ctx, req)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, resp.RejectionReason, "not enough capacity")
+	assert.Equal(t, resp.RetryAt, Some(retryAt))
+
+	resp, err = c.ChangeCommitments(ctx, req)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, resp, liquid.CommitmentChangeResponse{})
+
+	// ServiceInfo can be replaced, and the InfoVersion in reports follows along
+	info := testServiceInfo.Clone()
+	info.Version = 2
+	assert.ErrEqual(t, fake.SetServiceInfo(ctx, info), nil)
+	newInfo, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, newInfo.Version, 2)
+
+	info.Resources["things"] = liquid.ResourceInfo{Topology: "weird"}
+	err = fake.SetServiceInfo(ctx, info)
+	assert.ErrEqual(t, err, `received ServiceInfo is invalid: .Resources["things"] has invalid topology "weird"`)
+	newInfo, err = c.GetInfo(ctx)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, newInfo.Version, 2)
+}
@@ -24,6 +24,18 @@ const (
 	MetricTypeSummary        MetricType = "summary"
 )
 
+// IsValid returns whether the given value is a part of the enum.
+// This can be used to check unmarshalled values.
+func (t MetricType) IsValid() bool {
+	switch t {
+	case MetricTypeUnknown, MetricTypeGauge, MetricTypeCounter, MetricTypeStateset, MetricTypeInfo,
+		MetricTypeHistogram, MetricTypeGaugeHistogram, MetricTypeSummary:
+		return true
+	default:
+		return false
+	}
+}
+
 // MetricFamilyInfo describes a metric family.
 // This type appears in type [ServiceInfo].
 // For more information, please refer to the "Metrics" section of the package documentation.
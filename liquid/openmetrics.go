@@ -0,0 +1,492 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OpenMetricsContentType is the value for the Content-Type header that shall be used when serving the output of func [WriteOpenMetrics] via HTTP.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+var (
+	openMetricsNameRx      = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	openMetricsLabelNameRx = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// WriteOpenMetrics renders the given metric families and metrics in the [OpenMetrics format].
+// The arguments are usually taken from [ServiceInfo] and [ServiceCapacityReport] or [ServiceUsageReport], respectively.
+// If constLabels is not empty, those labels are added to each metric.
+//
+// Since the OpenMetrics format has slightly different naming rules than LIQUID, the following conversions are applied:
+//   - For MetricTypeCounter, the metric family name should end in "_total".
+//     This suffix is removed from the family name in the HELP and TYPE lines, but retained on the samples.
+//     (If the suffix is missing, it is added to the sample names.)
+//   - For MetricTypeInfo, the same applies with the suffix "_info".
+//   - For MetricTypeHistogram and MetricTypeGaugeHistogram, each metric is rendered as a bucket sample with the suffix "_bucket".
+//     Each metric must have a label "le", and the bucket with le="+Inf" must be the last one in its label set.
+//     Since LIQUID does not have a separate representation for counts, "_count" or "_gcount" samples are derived from the "+Inf" buckets.
+//
+// An error is returned if metrics are given for undeclared metric families, if metrics do not have the declared number of labels,
+// or if any names are not acceptable in the OpenMetrics format.
+//
+// [OpenMetrics format]: https://github.com/OpenObservability/OpenMetrics/blob/master/specification/OpenMetrics.md
+func WriteOpenMetrics(w io.Writer, families map[MetricName]MetricFamilyInfo, metrics map[MetricName][]Metric, constLabels map[string]string) error {
+	constLabelKeys := slices.Sorted(maps.Keys(constLabels))
+	for _, key := range constLabelKeys {
+		if !openMetricsLabelNameRx.MatchString(key) {
+			return fmt.Errorf("invalid label name %q in constant labels", key)
+		}
+	}
+	for name := range metrics {
+		if _, exists := families[name]; !exists {
+			return fmt.Errorf("found metrics for undeclared metric family %q", name)
+		}
+	}
+
+	// check names of metric families
+	familyNames := slices.Sorted(maps.Keys(families))
+	familyNameByOMName := make(map[string]MetricName, len(families))
+	for _, name := range familyNames {
+		info := families[name]
+		omName := openMetricsFamilyName(name, info.Type)
+		if !openMetricsNameRx.MatchString(omName) {
+			return fmt.Errorf("invalid metric family name %q", name)
+		}
+		if other, exists := familyNameByOMName[omName]; exists {
+			return fmt.Errorf("metric families %q and %q cannot both be rendered as %q", other, name, omName)
+		}
+		familyNameByOMName[omName] = name
+
+		for _, key := range info.LabelKeys {
+			if !openMetricsLabelNameRx.MatchString(key) {
+				return fmt.Errorf("invalid label name %q in metric family %q", key, name)
+			}
+			if _, exists := constLabels[key]; exists {
+				return fmt.Errorf("constant label %q conflicts with label of the same name in metric family %q", key, name)
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, name := range familyNames {
+		info := families[name]
+		omName := openMetricsFamilyName(name, info.Type)
+		if info.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", omName, escapeOpenMetricsString(info.Help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", omName, info.Type)
+
+		leIndex := slices.Index(info.LabelKeys, "le")
+		for idx, metric := range metrics[name] {
+			if len(metric.LabelValues) != len(info.LabelKeys) {
+				return fmt.Errorf("expected %d label values for metric family %q, but .Metrics[%q][%d] has %d",
+					len(info.LabelKeys), name, name, idx, len(metric.LabelValues))
+			}
+
+			switch info.Type {
+			case MetricTypeCounter:
+				writeOpenMetricsSample(bw, omName+"_total", info.LabelKeys, metric.LabelValues, -1, constLabelKeys, constLabels, metric.Value)
+			case MetricTypeInfo:
+				writeOpenMetricsSample(bw, omName+"_info", info.LabelKeys, metric.LabelValues, -1, constLabelKeys, constLabels, metric.Value)
+			case MetricTypeHistogram, MetricTypeGaugeHistogram:
+				if leIndex < 0 {
+					return fmt.Errorf("metric family %q is of type %q, but does not have a label \"le\"", name, info.Type)
+				}
+				writeOpenMetricsSample(bw, omName+"_bucket", info.LabelKeys, metric.LabelValues, -1, constLabelKeys, constLabels, metric.Value)
+				if le, err := strconv.ParseFloat(metric.LabelValues[leIndex], 64); err == nil && math.IsInf(le, +1) {
+					countSuffix := "_count"
+					if info.Type == MetricTypeGaugeHistogram {
+						countSuffix = "_gcount"
+					}
+					writeOpenMetricsSample(bw, omName+countSuffix, info.LabelKeys, metric.LabelValues, leIndex, constLabelKeys, constLabels, metric.Value)
+				}
+			default:
+				writeOpenMetricsSample(bw, omName, info.LabelKeys, metric.LabelValues, -1, constLabelKeys, constLabels, metric.Value)
+			}
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// openMetricsFamilyName returns the name of the OpenMetrics metric family corresponding to the given LIQUID metric family.
+func openMetricsFamilyName(name MetricName, metricType MetricType) string {
+	switch metricType {
+	case MetricTypeCounter:
+		return strings.TrimSuffix(string(name), "_total")
+	case MetricTypeInfo:
+		return strings.TrimSuffix(string(name), "_info")
+	default:
+		return string(name)
+	}
+}
+
+// liquidMetricFamilyName is the reverse of openMetricsFamilyName.
+// In the Prometheus text format, counter families are usually declared with the "_total" suffix already included.
+func liquidMetricFamilyName(omName string, metricType MetricType) MetricName {
+	suffix := ""
+	switch metricType {
+	case MetricTypeCounter:
+		suffix = "_total"
+	case MetricTypeInfo:
+		suffix = "_info"
+	}
+	if strings.HasSuffix(omName, suffix) {
+		return MetricName(omName)
+	}
+	return MetricName(omName + suffix)
+}
+
+// If skipIndex >= 0, the label with that index is not rendered.
+func writeOpenMetricsSample(bw *bufio.Writer, sampleName string, labelKeys, labelValues []string, skipIndex int, constLabelKeys []string, constLabels map[string]string, value float64) {
+	labelCount := len(labelKeys) + len(constLabelKeys)
+	if skipIndex >= 0 {
+		labelCount--
+	}
+	bw.WriteString(sampleName)
+	if labelCount > 0 {
+		bw.WriteByte('{')
+		isFirst := true
+		writeLabel := func(key, value string) {
+			if !isFirst {
+				bw.WriteByte(',')
+			}
+			isFirst = false
+			fmt.Fprintf(bw, `%s="%s"`, key, escapeOpenMetricsString(value))
+		}
+		for idx, key := range labelKeys {
+			if idx != skipIndex {
+				writeLabel(key, labelValues[idx])
+			}
+		}
+		for _, key := range constLabelKeys {
+			writeLabel(key, constLabels[key])
+		}
+		bw.WriteByte('}')
+	}
+	bw.WriteByte(' ')
+	bw.WriteString(formatOpenMetricsValue(value))
+	bw.WriteByte('\n')
+}
+
+var openMetricsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeOpenMetricsString(s string) string {
+	return openMetricsEscaper.Replace(s)
+}
+
+func formatOpenMetricsValue(value float64) string {
+	switch {
+	case math.IsInf(value, +1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// ParseOpenMetrics parses metrics in the [OpenMetrics format] or in the older Prometheus text format, e.g. as obtained from a /metrics endpoint.
+// This can be used by liquids to import metrics from their backend service into their capacity or usage reports.
+// The result is suitable for use in [ServiceInfo] and in [ServiceCapacityReport] or [ServiceUsageReport].
+//
+// This is the reverse of func [WriteOpenMetrics], with the same naming conventions.
+// Since LIQUID has a simpler data model than OpenMetrics, the following information is lost during parsing:
+//   - For MetricTypeCounter, "_created" samples are ignored.
+//   - For MetricTypeHistogram and MetricTypeGaugeHistogram, only "_bucket" samples are retained.
+//   - For MetricTypeSummary, only quantile samples are retained. "_sum", "_count" and "_created" samples are ignored.
+//   - Timestamps, exemplars and UNIT metadata are ignored.
+//   - If samples within a metric family have different label keys, the LabelKeys of the family are the union of all those label keys.
+//     Missing labels are reported with an empty label value, which is equivalent per the OpenMetrics format.
+//
+// Samples that do not belong to any declared metric family are reported in a metric family of type MetricTypeUnknown.
+//
+// [OpenMetrics format]: https://github.com/OpenObservability/OpenMetrics/blob/master/specification/OpenMetrics.md
+func ParseOpenMetrics(r io.Reader) (map[MetricName]MetricFamilyInfo, map[MetricName][]Metric, error) {
+	type family struct {
+		Type    MetricType
+		Help    string
+		Samples []openMetricsSample
+	}
+	familiesByOMName := make(map[string]*family)
+	getFamily := func(omName string) *family {
+		f, exists := familiesByOMName[omName]
+		if !exists {
+			f = &family{Type: MetricTypeUnknown}
+			familiesByOMName[omName] = f
+		}
+		return f
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	lineNo := 0
+LINE:
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// parse metadata
+		if rest, ok := strings.CutPrefix(line, "#"); ok {
+			fields := strings.SplitN(strings.TrimLeft(rest, " "), " ", 3)
+			switch fields[0] {
+			case "EOF":
+				break LINE
+			case "HELP":
+				if len(fields) < 2 {
+					return nil, nil, fmt.Errorf("line %d: missing metric name in HELP line", lineNo)
+				}
+				help := ""
+				if len(fields) == 3 {
+					help = unescapeOpenMetricsString(fields[2])
+				}
+				getFamily(fields[1]).Help = help
+			case "TYPE":
+				if len(fields) < 3 {
+					return nil, nil, fmt.Errorf("line %d: missing metric name or type in TYPE line", lineNo)
+				}
+				metricType, err := parseOpenMetricsType(strings.TrimSpace(fields[2]))
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				getFamily(fields[1]).Type = metricType
+			}
+			// UNIT lines and other comments are ignored
+			continue
+		}
+
+		// parse sample
+		sample, err := parseOpenMetricsSample(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		omName, keep := resolveOpenMetricsSample(sample.Name, func(omName string) (MetricType, bool) {
+			f, exists := familiesByOMName[omName]
+			if !exists {
+				return "", false
+			}
+			return f.Type, true
+		})
+		if keep {
+			f := getFamily(omName)
+			f.Samples = append(f.Samples, sample)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// convert into LIQUID structures
+	families := make(map[MetricName]MetricFamilyInfo, len(familiesByOMName))
+	metrics := make(map[MetricName][]Metric, len(familiesByOMName))
+	for omName, f := range familiesByOMName {
+		name := liquidMetricFamilyName(omName, f.Type)
+		if _, exists := families[name]; exists {
+			return nil, nil, fmt.Errorf("multiple metric families map to the same LIQUID metric family %q", name)
+		}
+
+		labelKeySet := make(map[string]struct{})
+		for _, s := range f.Samples {
+			for key := range s.Labels {
+				labelKeySet[key] = struct{}{}
+			}
+		}
+		labelKeys := slices.Sorted(maps.Keys(labelKeySet))
+		families[name] = MetricFamilyInfo{
+			Type:      f.Type,
+			Help:      f.Help,
+			LabelKeys: labelKeys,
+		}
+
+		if len(f.Samples) == 0 {
+			continue
+		}
+		familyMetrics := make([]Metric, len(f.Samples))
+		for idx, s := range f.Samples {
+			var labelValues []string
+			if len(labelKeys) > 0 {
+				labelValues = make([]string, len(labelKeys))
+			}
+			for keyIdx, key := range labelKeys {
+				labelValues[keyIdx] = s.Labels[key]
+			}
+			familyMetrics[idx] = Metric{Value: s.Value, LabelValues: labelValues}
+		}
+		metrics[name] = familyMetrics
+	}
+	return families, metrics, nil
+}
+
+func parseOpenMetricsType(input string) (MetricType, error) {
+	metricType := MetricType(input)
+	switch {
+	case metricType.IsValid():
+		return metricType, nil
+	case input == "untyped": // from the Prometheus text format
+		return MetricTypeUnknown, nil
+	default:
+		return "", fmt.Errorf("unknown metric type %q", input)
+	}
+}
+
+// resolveOpenMetricsSample finds the metric family that a sample with the given name belongs to.
+// If the sample is not retained in the LIQUID data model (e.g. "_sum" samples on histograms), false is returned.
+func resolveOpenMetricsSample(sampleName string, getFamilyType func(string) (MetricType, bool)) (omName string, keep bool) {
+	if _, exists := getFamilyType(sampleName); exists {
+		return sampleName, true
+	}
+
+	suffixes := map[MetricType]map[string]bool{
+		MetricTypeCounter:        {"_total": true, "_created": false},
+		MetricTypeInfo:           {"_info": true},
+		MetricTypeHistogram:      {"_bucket": true, "_count": false, "_sum": false, "_created": false},
+		MetricTypeGaugeHistogram: {"_bucket": true, "_gcount": false, "_gsum": false},
+		MetricTypeSummary:        {"_count": false, "_sum": false, "_created": false},
+	}
+	for idx := strings.LastIndexByte(sampleName, '_'); idx > 0; idx = strings.LastIndexByte(sampleName[:idx], '_') {
+		familyType, exists := getFamilyType(sampleName[:idx])
+		if !exists {
+			continue
+		}
+		if keep, isKnownSuffix := suffixes[familyType][sampleName[idx:]]; isKnownSuffix {
+			return sampleName[:idx], keep
+		}
+	}
+
+	// sample does not belong to any declared metric family
+	return sampleName, true
+}
+
+type openMetricsSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// parseOpenMetricsSample parses a line like `name{key="value",...} 42 [timestamp] [# exemplar]`.
+func parseOpenMetricsSample(line string) (openMetricsSample, error) {
+	sample := openMetricsSample{Labels: make(map[string]string)}
+
+	nameEnd := strings.IndexAny(line, "{ ")
+	if nameEnd < 0 {
+		return sample, errors.New("missing value for sample")
+	}
+	sample.Name = line[:nameEnd]
+	if !openMetricsNameRx.MatchString(sample.Name) {
+		return sample, fmt.Errorf("invalid metric name %q", sample.Name)
+	}
+	rest := line[nameEnd:]
+
+	// parse label set
+	if strings.HasPrefix(rest, "{") {
+		rest = rest[1:]
+		for {
+			rest = strings.TrimLeft(rest, " ")
+			if after, ok := strings.CutPrefix(rest, "}"); ok {
+				rest = after
+				break
+			}
+
+			key, after, ok := strings.Cut(rest, "=")
+			key = strings.TrimSpace(key)
+			if !ok || !openMetricsLabelNameRx.MatchString(key) {
+				return sample, fmt.Errorf("malformed label set on sample %q", sample.Name)
+			}
+			value, after, err := parseOpenMetricsQuotedString(strings.TrimLeft(after, " "))
+			if err != nil {
+				return sample, fmt.Errorf("malformed value for label %q on sample %q: %w", key, sample.Name, err)
+			}
+			if _, exists := sample.Labels[key]; exists {
+				return sample, fmt.Errorf("duplicate label %q on sample %q", key, sample.Name)
+			}
+			sample.Labels[key] = value
+
+			rest = strings.TrimLeft(after, " ")
+			if after, ok := strings.CutPrefix(rest, ","); ok {
+				rest = after
+			} else if !strings.HasPrefix(rest, "}") {
+				return sample, fmt.Errorf("malformed label set on sample %q", sample.Name)
+			}
+		}
+	}
+
+	// parse value (the timestamp and exemplar, if any, are ignored)
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("missing value for sample %q", sample.Name)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("malformed value for sample %q: %q", sample.Name, fields[0])
+	}
+	sample.Value = value
+	return sample, nil
+}
+
+// parseOpenMetricsQuotedString parses a string literal from the start of the input,
+// and returns the unescaped string as well as the remainder of the input.
+func parseOpenMetricsQuotedString(input string) (value, rest string, err error) {
+	if !strings.HasPrefix(input, `"`) {
+		return "", "", errors.New("expected string literal")
+	}
+	var sb strings.Builder
+	for idx := 1; idx < len(input); idx++ {
+		switch input[idx] {
+		case '"':
+			return sb.String(), input[idx+1:], nil
+		case '\\':
+			idx++
+			if idx == len(input) {
+				return "", "", errors.New("unterminated string literal")
+			}
+			switch input[idx] {
+			case 'n':
+				sb.WriteByte('\n')
+			case '\\', '"':
+				sb.WriteByte(input[idx])
+			default:
+				return "", "", fmt.Errorf("invalid escape sequence %q", input[idx-1:idx+1])
+			}
+		default:
+			sb.WriteByte(input[idx])
+		}
+	}
+	return "", "", errors.New("unterminated string literal")
+}
+
+func unescapeOpenMetricsString(s string) string {
+	var sb strings.Builder
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] == '\\' && idx+1 < len(s) {
+			idx++
+			switch s[idx] {
+			case 'n':
+				sb.WriteByte('\n')
+			case '\\', '"':
+				sb.WriteByte(s[idx])
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(s[idx])
+			}
+			continue
+		}
+		sb.WriteByte(s[idx])
+	}
+	return sb.String()
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+)
+
+var openMetricsTestFamilies = map[MetricName]MetricFamilyInfo{
+	"build_info": {
+		Type:      MetricTypeInfo,
+		Help:      "Build information.",
+		LabelKeys: []string{"version"},
+	},
+	"request_duration_seconds": {
+		Type:      MetricTypeHistogram,
+		Help:      "Duration of API requests.",
+		LabelKeys: []string{"le", "method"},
+	},
+	"requests_total": {
+		Type:      MetricTypeCounter,
+		Help:      "Number of API requests.\nIncludes failed requests.",
+		LabelKeys: []string{"method", "path"},
+	},
+	"temperature": {
+		Type:      MetricTypeGauge,
+		Help:      "",
+		LabelKeys: nil,
+	},
+}
+
+var openMetricsTestMetrics = map[MetricName][]Metric{
+	"build_info": {
+		{Value: 1, LabelValues: []string{"1.2.3"}},
+	},
+	"request_duration_seconds": {
+		{Value: 3, LabelValues: []string{"0.5", "GET"}},
+		{Value: 5, LabelValues: []string{"+Inf", "GET"}},
+		{Value: 0, LabelValues: []string{"0.5", "PUT"}},
+		{Value: 1, LabelValues: []string{"+Inf", "PUT"}},
+	},
+	"requests_total": {
+		{Value: 42, LabelValues: []string{"GET", `/v1/"quoted"\path`}},
+		{Value: 1e10, LabelValues: []string{"PUT", "/v1/projects"}},
+	},
+	"temperature": {
+		{Value: math.Inf(-1), LabelValues: nil},
+	},
+}
+
+const openMetricsTestOutput = `# HELP build Build information.
+# TYPE build info
+build_info{version="1.2.3",service="compute"} 1
+# HELP request_duration_seconds Duration of API requests.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.5",method="GET",service="compute"} 3
+request_duration_seconds_bucket{le="+Inf",method="GET",service="compute"} 5
+request_duration_seconds_count{method="GET",service="compute"} 5
+request_duration_seconds_bucket{le="0.5",method="PUT",service="compute"} 0
+request_duration_seconds_bucket{le="+Inf",method="PUT",service="compute"} 1
+request_duration_seconds_count{method="PUT",service="compute"} 1
+# HELP requests Number of API requests.\nIncludes failed requests.
+# TYPE requests counter
+requests_total{method="GET",path="/v1/\"quoted\"\\path",service="compute"} 42
+requests_total{method="PUT",path="/v1/projects",service="compute"} 1e+10
+# TYPE temperature gauge
+temperature{service="compute"} -Inf
+# EOF
+`
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var sb strings.Builder
+	err := WriteOpenMetrics(&sb, openMetricsTestFamilies, openMetricsTestMetrics, map[string]string{"service": "compute"})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, sb.String(), openMetricsTestOutput)
+
+	// error cases
+	err = WriteOpenMetrics(&sb, openMetricsTestFamilies, openMetricsTestMetrics, map[string]string{"method": "GET"})
+	assert.ErrEqual(t, err, `constant label "method" conflicts with label of the same name in metric family "request_duration_seconds"`)
+
+	err = WriteOpenMetrics(&sb, openMetricsTestFamilies, map[MetricName][]Metric{"unknown": nil}, nil)
+	assert.ErrEqual(t, err, `found metrics for undeclared metric family "unknown"`)
+
+	err = WriteOpenMetrics(&sb, openMetricsTestFamilies, map[MetricName][]Metric{"temperature": {{Value: 1, LabelValues: []string{"foo"}}}}, nil)
+	assert.ErrEqual(t, err, `expected 0 label values for metric family "temperature", but .Metrics["temperature"][0] has 1`)
+
+	err = WriteOpenMetrics(&sb, map[MetricName]MetricFamilyInfo{
+		"requests_total": {Type: MetricTypeCounter},
+		"requests":       {Type: MetricTypeGauge},
+	}, nil, nil)
+	assert.ErrEqual(t, err, `metric families "requests" and "requests_total" cannot both be rendered as "requests"`)
+}
+
+func TestParseOpenMetricsRoundTrip(t *testing.T) {
+	var sb strings.Builder
+	err := WriteOpenMetrics(&sb, openMetricsTestFamilies, openMetricsTestMetrics, nil)
+	assert.ErrEqual(t, err, nil)
+
+	families, metrics, err := ParseOpenMetrics(strings.NewReader(sb.String()))
+	assert.ErrEqual(t, err, nil)
+	assert.DeepEqual(t, "families", families, openMetricsTestFamilies)
+	assert.DeepEqual(t, "metrics", metrics, openMetricsTestMetrics)
+}
+
+func TestParseOpenMetricsFromPrometheus(t *testing.T) {
+	input := `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{code="400"} 3 1395066363000
+
+# A comment that is ignored.
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773
+rpc_duration_seconds{quantile="0.99"} 76656
+rpc_duration_seconds_sum 1.7560473e+07
+rpc_duration_seconds_count 2693
+
+# HELP queue_length Current length of the queue.
+# TYPE queue_length gauge
+# UNIT queue_length items
+queue_length{queue="a\nb"} 12 # {trace_id="abc"} 1.0
+
+# TYPE process_start_time_seconds untyped
+process_start_time_seconds 1.7e+09
+undeclared_metric NaN
+# EOF
+ignored_after_eof 1
+`
+	families, metrics, err := ParseOpenMetrics(strings.NewReader(input))
+	assert.ErrEqual(t, err, nil)
+	assert.DeepEqual(t, "families", families, map[MetricName]MetricFamilyInfo{
+		"http_requests_total": {
+			Type:      MetricTypeCounter,
+			Help:      "The total number of HTTP requests.",
+			LabelKeys: []string{"code", "method"},
+		},
+		"rpc_duration_seconds": {
+			Type:      MetricTypeSummary,
+			LabelKeys: []string{"quantile"},
+		},
+		"queue_length": {
+			Type:      MetricTypeGauge,
+			Help:      "Current length of the queue.",
+			LabelKeys: []string{"queue"},
+		},
+		"process_start_time_seconds": {
+			Type: MetricTypeUnknown,
+		},
+		"undeclared_metric": {
+			Type: MetricTypeUnknown,
+		},
+	})
+
+	nan := metrics["undeclared_metric"][0].Value
+	assert.Equal(t, math.IsNaN(nan), true)
+	delete(metrics, "undeclared_metric")
+	assert.DeepEqual(t, "metrics", metrics, map[MetricName][]Metric{
+		"http_requests_total": {
+			{Value: 1027, LabelValues: []string{"200", "post"}},
+			{Value: 3, LabelValues: []string{"400", ""}},
+		},
+		"rpc_duration_seconds": {
+			{Value: 4773, LabelValues: []string{"0.5"}},
+			{Value: 76656, LabelValues: []string{"0.99"}},
+		},
+		"queue_length": {
+			{Value: 12, LabelValues: []string{"a\nb"}},
+		},
+		"process_start_time_seconds": {
+			{Value: 1.7e+09, LabelValues: nil},
+		},
+	})
+}
+
+func TestParseOpenMetricsErrors(t *testing.T) {
+	testCases := map[string]string{
+		"# TYPE foo bar\n":             `line 1: unknown metric type "bar"`,
+		"foo\n":                        `line 1: missing value for sample`,
+		"foo{bar=\"baz\" 1\n":          `line 1: malformed label set on sample "foo"`,
+		"foo{bar=\"baz\\x\"} 1\n":      `line 1: malformed value for label "bar" on sample "foo": invalid escape sequence "\\x"`,
+		"foo{bar=\"1\",bar=\"2\"} 1\n": `line 1: duplicate label "bar" on sample "foo"`,
+		"\n\nfoo bar\n":                `line 3: malformed value for sample "foo": "bar"`,
+		"# TYPE foo counter\nfoo 1\nfoo_total 2\n# TYPE foo_total gauge\n": `multiple metric families map to the same LIQUID metric family "foo_total"`,
+	}
+	for input, expectedError := range testCases {
+		_, _, err := ParseOpenMetrics(strings.NewReader(input))
+		assert.ErrEqual(t, err, expectedError)
+	}
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid
+
+import "embed"
+
+//go:generate go run ../internal/cmd/liquid-schemagen
+
+// JSONSchemas contains JSON schemas (draft 2020-12) for all request and response bodies of the LIQUID API,
+// for the benefit of liquid implementations that are not written in Go.
+// For each payload type, there is a file "schemas/<TypeName>.schema.json", e.g. "schemas/ServiceInfo.schema.json".
+//
+// These schemas are generated from the type declarations in this package.
+// They describe the serialization of these types through encoding/json, but they do not cover all checks done by the Validate functions in this package.
+//
+//go:embed schemas/*.schema.json
+var JSONSchemas embed.FS
+
+// OpenAPIDocument is an OpenAPI 3.1 document describing the LIQUID API.
+// It is generated from the "Endpoint:" sections of the package documentation, and refers to the same payload types as [JSONSchemas].
+//
+//go:embed schemas/openapi.json
+var OpenAPIDocument []byte
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquid_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+
+	"github.com/sapcc/go-api-declarations/internal/jsonschema"
+	"github.com/sapcc/go-api-declarations/internal/liquidschema"
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+func TestJSONSchemasAreUpToDate(t *testing.T) {
+	files, err := liquidschema.Generate(".")
+	assert.ErrEqual(t, err, nil)
+
+	for name, expected := range files {
+		actual, err := os.ReadFile(filepath.Join("schemas", name))
+		assert.ErrEqual(t, err, nil)
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("schemas/%s is not up to date, please run `go generate ./liquid`", name)
+		}
+	}
+
+	entries, err := os.ReadDir("schemas")
+	assert.ErrEqual(t, err, nil)
+	for _, entry := range entries {
+		if files[entry.Name()] == nil {
+			t.Errorf("schemas/%s is not generated anymore and should be deleted", entry.Name())
+		}
+	}
+	assert.Equal(t, string(liquid.OpenAPIDocument), string(files[liquidschema.OpenAPIFileName]))
+}
+
+func loadSchema(t *testing.T, typ reflect.Type) *jsonschema.Schema {
+	t.Helper()
+	buf, err := liquid.JSONSchemas.ReadFile("schemas/" + liquidschema.FileNameFor(typ))
+	assert.ErrEqual(t, err, nil)
+	var schema jsonschema.Schema
+	assert.ErrEqual(t, json.Unmarshal(buf, &schema), nil)
+	return &schema
+}
+
+func TestJSONSchemasRoundTripFixtures(t *testing.T) {
+	for _, typ := range liquidschema.PayloadTypes {
+		t.Run(typ.Name(), func(t *testing.T) {
+			schema := loadSchema(t, typ)
+			fixture, err := os.ReadFile(filepath.Join("testdata", typ.Name()+".json"))
+			assert.ErrEqual(t, err, nil)
+			assert.ErrEqual(t, schema.Validate(fixture), nil)
+
+			// the fixture must survive a round-trip through the Go type unchanged
+			value := reflect.New(typ)
+			assert.ErrEqual(t, json.Unmarshal(fixture, value.Interface()), nil)
+			reencoded, err := json.Marshal(value.Interface())
+			assert.ErrEqual(t, err, nil)
+			assert.DeepEqual(t, "re-encoded fixture", decodeGeneric(t, reencoded), decodeGeneric(t, fixture))
+			assert.ErrEqual(t, schema.Validate(reencoded), nil)
+		})
+	}
+}
+
+func decodeGeneric(t *testing.T, buf []byte) any {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var result any
+	assert.ErrEqual(t, dec.Decode(&result), nil)
+	return result
+}
+
+func TestJSONSchemasRejectInvalidPayloads(t *testing.T) {
+	infoSchema := loadSchema(t, reflect.TypeFor[liquid.ServiceInfo]())
+	err := infoSchema.Validate([]byte(`{
+		"version": 1, "displayName": "Foo", "categories": {},
+		"resources": {
+			"things": {"displayName": "", "unit": "3 bananas", "topology": "weird", "hasCapacity": false, "needsResourceDemand": false, "hasQuota": false},
+			"-invalid": {"displayName": "", "topology": "flat", "hasCapacity": false, "needsResourceDemand": false}
+		},
+		"rates": {}, "capacityMetricFamilies": {},
+		"usageMetricFamilies": {"foo": {"type": "gauge", "help": "", "labelKeys": "bar"}}
+	}`))
+	assert.ErrEqual(t, err, `document does not conform to schema: `+
+		`at /resources/-invalid: expected a string matching /^[a-zA-Z][a-zA-Z0-9._-]*$/, but got "-invalid"; `+
+		`at /resources/-invalid: missing required property "hasQuota"; `+
+		`at /resources/things/topology: expected one of "flat", "az-aware", "az-separated", but got "weird"; `+
+		`at /resources/things/unit: expected a string matching /^(|piece|EiB|PiB|TiB|GiB|MiB|KiB|EB|PB|TB|GB|MB|kB|B|Ebit|Pbit|Tbit|Gbit|Mbit|kbit|bit|h|min|s|[0-9]+ (piece|EiB|PiB|TiB|GiB|MiB|KiB|EB|PB|TB|GB|MB|kB|B|Ebit|Pbit|Tbit|Gbit|Mbit|kbit|bit|h|min|s))$/, but got "3 bananas"; `+
+		`at /usageMetricFamilies/foo/labelKeys: expected a value of type "array" or "null", but got "bar"`)
+
+	reportSchema := loadSchema(t, reflect.TypeFor[liquid.ServiceUsageReport]())
+	err = reportSchema.Validate([]byte(`{
+		"infoVersion": 1,
+		"resources": {"things": {"forbidden": false, "perAZ": {"total": {"usage": -1}, "": {"usage": 1.5}}}},
+		"rates": {"transfer": {"perAZ": {"any": {"usage": "42"}}}}
+	}`))
+	assert.ErrEqual(t, err, `document does not conform to schema: `+
+		`at /rates/transfer/perAZ/any/usage: expected a value of type "integer" or "null", but got "42"; `+
+		`at /resources/things/perAZ/: expected a string with at least 1 characters, but got ""; `+
+		`at /resources/things/perAZ//usage: expected a value of type "integer", but got 1.5; `+
+		`at /resources/things/perAZ/total: "total" is not acceptable here; `+
+		`at /resources/things/perAZ/total/usage: expected a value of at least 0, but got -1`)
+
+	responseSchema := loadSchema(t, reflect.TypeFor[liquid.CommitmentChangeResponse]())
+	err = responseSchema.Validate([]byte(`{"retryAt": "tomorrow"}`))
+	assert.ErrEqual(t, err, `document does not conform to schema: at /retryAt: expected an RFC 3339 timestamp, but got "tomorrow"`)
+}
@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package liquidserver contains a reusable HTTP handler for implementing a liquid.
+// The routing, request decoding and error conventions from the LIQUID specification in package liquid are implemented by [Handler],
+// while the actual behavior of the liquid is provided by an implementation of interface [Logic].
+package liquidserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/sapcc/go-api-declarations/liquid"
+)
+
+// Logic is the interface that a liquid implementation needs to provide to [NewHandler].
+//
+// Each method except BuildServiceInfo receives the ServiceInfo that was most recently returned by BuildServiceInfo.
+// The handler validates all reports returned by these methods against this ServiceInfo before sending them to the client.
+// Commitment change requests are also validated against this ServiceInfo before being given to ReviewCommitmentChange.
+//
+// If a method returns an error of type [HTTPError], the respective status code and message will be used in the error response.
+// Any other error results in status 500 (Internal Server Error).
+type Logic interface {
+	// BuildServiceInfo is called by NewHandler and by Handler.ReloadServiceInfo to obtain the response for GET /v1/info.
+	BuildServiceInfo(ctx context.Context) (liquid.ServiceInfo, error)
+	// ScanCapacity is called for POST /v1/report-capacity.
+	ScanCapacity(ctx context.Context, req liquid.ServiceCapacityRequest, info liquid.ServiceInfo) (liquid.ServiceCapacityReport, error)
+	// ScanUsage is called for POST /v1/projects/:uuid/report-usage.
+	ScanUsage(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceUsageRequest, info liquid.ServiceInfo) (liquid.ServiceUsageReport, error)
+	// SetQuota is called for PUT /v1/projects/:uuid/quota.
+	SetQuota(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceQuotaRequest, info liquid.ServiceInfo) error
+	// ReviewCommitmentChange is called for POST /v1/change-commitments.
+	ReviewCommitmentChange(ctx context.Context, req liquid.CommitmentChangeRequest, info liquid.ServiceInfo) (liquid.CommitmentChangeResponse, error)
+}
+
+// Opts contains optional configuration for a [Handler].
+type Opts struct {
+	// If not nil, this function is called for each request before any other processing takes place.
+	// It shall check the Keystone token in the "X-Auth-Token" header of the request.
+	// As per the LIQUID specification, it should return an HTTPError with status 401 (Unauthorized) if the token is not valid,
+	// or an HTTPError with status 403 (Forbidden) if the token confers insufficient access.
+	//
+	// If nil, authentication is not checked at all.
+	// This is only advisable if authentication is enforced by some other component in front of the Handler.
+	Authorize func(r *http.Request) error
+}
+
+// HTTPError is an error that results in an error response with a specific status code.
+// It can be returned by the methods of [Logic] and by [Opts.Authorize].
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the builtin/error interface.
+func (e HTTPError) Error() string {
+	return e.Message
+}
+
+// Handler is an http.Handler that serves all endpoints from the LIQUID specification.
+// Instances must be created through [NewHandler].
+type Handler struct {
+	logic Logic
+	opts  Opts
+	mux   *http.ServeMux
+
+	infoMutex sync.RWMutex
+	info      liquid.ServiceInfo
+}
+
+// NewHandler builds a Handler that serves the given Logic.
+// Logic.BuildServiceInfo is called once to populate the initial ServiceInfo.
+func NewHandler(ctx context.Context, logic Logic, opts Opts) (*Handler, error) {
+	h := &Handler{logic: logic, opts: opts}
+	err := h.ReloadServiceInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("GET /v1/info", h.getInfo)
+	h.mux.HandleFunc("POST /v1/report-capacity", h.reportCapacity)
+	h.mux.HandleFunc("POST /v1/projects/{uuid}/report-usage", h.reportUsage)
+	h.mux.HandleFunc("PUT /v1/projects/{uuid}/quota", h.putQuota)
+	h.mux.HandleFunc("POST /v1/change-commitments", h.changeCommitments)
+	return h, nil
+}
+
+// ReloadServiceInfo calls Logic.BuildServiceInfo to replace the cached ServiceInfo.
+// This should be called whenever the liquid notices that its ServiceInfo has changed.
+// If the new ServiceInfo is not valid, an error is returned and the previous ServiceInfo remains in use.
+func (h *Handler) ReloadServiceInfo(ctx context.Context) error {
+	info, err := h.logic.BuildServiceInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot build ServiceInfo: %w", err)
+	}
+	err = liquid.ValidateServiceInfo(info)
+	if err != nil {
+		return err
+	}
+
+	h.infoMutex.Lock()
+	defer h.infoMutex.Unlock()
+	h.info = info
+	return nil
+}
+
+// ServiceInfo returns a copy of the currently cached ServiceInfo.
+func (h *Handler) ServiceInfo() liquid.ServiceInfo {
+	h.infoMutex.RLock()
+	defer h.infoMutex.RUnlock()
+	return h.info.Clone()
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Authorize != nil {
+		err := h.opts.Authorize(r)
+		if err != nil {
+			respondWithError(w, err)
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) getInfo(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, h.ServiceInfo())
+}
+
+func (h *Handler) reportCapacity(w http.ResponseWriter, r *http.Request) {
+	var req liquid.ServiceCapacityRequest
+	if !decodeRequestBody(w, r, &req) {
+		return
+	}
+
+	info := h.ServiceInfo()
+	report, err := h.logic.ScanCapacity(r.Context(), req, info)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	err = liquid.ValidateCapacityReport(report, req, info)
+	if err != nil {
+		respondWithError(w, fmt.Errorf("could not produce a valid capacity report: %w", err))
+		return
+	}
+	respondWithJSON(w, report)
+}
+
+func (h *Handler) reportUsage(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := getProjectUUID(w, r)
+	if !ok {
+		return
+	}
+	var req liquid.ServiceUsageRequest
+	if !decodeRequestBody(w, r, &req) {
+		return
+	}
+	if !checkProjectMetadata(w, projectUUID, req.ProjectMetadata.UnwrapOr(liquid.ProjectMetadata{UUID: string(projectUUID)})) {
+		return
+	}
+
+	info := h.ServiceInfo()
+	report, err := h.logic.ScanUsage(r.Context(), projectUUID, req, info)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	err = liquid.ValidateUsageReport(report, req, info)
+	if err != nil {
+		respondWithError(w, fmt.Errorf("could not produce a valid usage report: %w", err))
+		return
+	}
+	respondWithJSON(w, report)
+}
+
+func (h *Handler) putQuota(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := getProjectUUID(w, r)
+	if !ok {
+		return
+	}
+	var req liquid.ServiceQuotaRequest
+	if !decodeRequestBody(w, r, &req) {
+		return
+	}
+	if !checkProjectMetadata(w, projectUUID, req.ProjectMetadata.UnwrapOr(liquid.ProjectMetadata{UUID: string(projectUUID)})) {
+		return
+	}
+
+	err := h.logic.SetQuota(r.Context(), projectUUID, req, h.ServiceInfo())
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) changeCommitments(w http.ResponseWriter, r *http.Request) {
+	var req liquid.CommitmentChangeRequest
+	if !decodeRequestBody(w, r, &req) {
+		return
+	}
+
+	info := h.ServiceInfo()
+	err := liquid.ValidateCommitmentChangeRequest(req, info)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.logic.ReviewCommitmentChange(r.Context(), req, info)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	respondWithJSON(w, resp)
+}
+
+// Keystone uses UUIDs without dashes as project IDs, but we also accept the usual UUID format.
+var projectUUIDRx = regexp.MustCompile(`^(?i:[0-9a-f]{32}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+
+func getProjectUUID(w http.ResponseWriter, r *http.Request) (liquid.ProjectUUID, bool) {
+	projectUUID := r.PathValue("uuid")
+	if !projectUUIDRx.MatchString(projectUUID) {
+		http.Error(w, fmt.Sprintf("malformed project UUID: %q", projectUUID), http.StatusBadRequest)
+		return "", false
+	}
+	return liquid.ProjectUUID(projectUUID), true
+}
+
+func checkProjectMetadata(w http.ResponseWriter, projectUUID liquid.ProjectUUID, metadata liquid.ProjectMetadata) bool {
+	if metadata.UUID != string(projectUUID) {
+		msg := fmt.Sprintf("project UUID in request path (%q) does not match project UUID in request body (%q)", projectUUID, metadata.UUID)
+		http.Error(w, msg, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func decodeRequestBody(w http.ResponseWriter, r *http.Request, target any) bool {
+	err := json.NewDecoder(r.Body).Decode(target)
+	if err != nil {
+		http.Error(w, "malformed request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func respondWithJSON(w http.ResponseWriter, data any) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf) //nolint:errcheck // nothing left to report the error to
+}
+
+func respondWithError(w http.ResponseWriter, err error) {
+	var herr HTTPError
+	if errors.As(err, &herr) {
+		http.Error(w, herr.Message, herr.StatusCode)
+	} else {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
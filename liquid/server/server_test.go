@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package liquidserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.xyrillian.de/gg/assert"
+	. "go.xyrillian.de/gg/option"
+
+	"github.com/sapcc/go-api-declarations/liquid"
+	liquidclient "github.com/sapcc/go-api-declarations/liquid/client"
+)
+
+const (
+	projectUUID  = "d41d8cd98f00b204e9800998ecf8427e"
+	projectUUID2 = "a2a9f0c6-9aa5-4e4b-95b7-cdc8da6ac2b8"
+)
+
+// A simple Logic implementation for a service with a single flat resource.
+type testLogic struct {
+	Version     int64
+	Quota       map[liquid.ProjectUUID]uint64
+	BrokenUsage bool // if true, ScanUsage returns an invalid report
+}
+
+func (l *testLogic) BuildServiceInfo(ctx context.Context) (liquid.ServiceInfo, error) {
+	return liquid.ServiceInfo{
+		Version: l.Version,
+		Resources: map[liquid.ResourceName]liquid.ResourceInfo{
+			"things": {
+				Unit:        liquid.UnitNone,
+				Topology:    liquid.FlatTopology,
+				HasCapacity: true,
+				HasQuota:    true,
+			},
+		},
+	}, nil
+}
+
+func (l *testLogic) ScanCapacity(ctx context.Context, req liquid.ServiceCapacityRequest, info liquid.ServiceInfo) (liquid.ServiceCapacityReport, error) {
+	return liquid.ServiceCapacityReport{
+		InfoVersion: info.Version,
+		Resources: map[liquid.ResourceName]*liquid.ResourceCapacityReport{
+			"things": {PerAZ: liquid.InAnyAZ(liquid.AZResourceCapacityReport{Capacity: 100})},
+		},
+	}, nil
+}
+
+func (l *testLogic) ScanUsage(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceUsageRequest, info liquid.ServiceInfo) (liquid.ServiceUsageReport, error) {
+	quota, exists := l.Quota[projectUUID]
+	if !exists {
+		return liquid.ServiceUsageReport{}, HTTPError{http.StatusNotFound, "no such project"}
+	}
+	report := liquid.ServiceUsageReport{
+		InfoVersion: info.Version,
+		Resources: map[liquid.ResourceName]*liquid.ResourceUsageReport{
+			"things": {
+				Quota: Some(int64(quota)), //nolint:gosec // test data is small
+				PerAZ: liquid.InAnyAZ(liquid.AZResourceUsageReport{Usage: 5}),
+			},
+		},
+	}
+	if l.BrokenUsage {
+		report.Resources["things"].Quota = None[int64]()
+	}
+	return report, nil
+}
+
+func (l *testLogic) SetQuota(ctx context.Context, projectUUID liquid.ProjectUUID, req liquid.ServiceQuotaRequest, info liquid.ServiceInfo) error {
+	l.Quota[projectUUID] = req.Resources["things"].Quota
+	return nil
+}
+
+func (l *testLogic) ReviewCommitmentChange(ctx context.Context, req liquid.CommitmentChangeRequest, info liquid.ServiceInfo) (liquid.CommitmentChangeResponse, error) {
+	if req.DryRun {
+		return liquid.CommitmentChangeResponse{}, nil
+	}
+	return liquid.CommitmentChangeResponse{RejectionReason: "not today"}, nil
+}
+
+func setupTest(t *testing.T, logic Logic, opts Opts) (*Handler, *liquidclient.Client) {
+	t.Helper()
+	h, err := NewHandler(t.Context(), logic, opts)
+	assert.ErrEqual(t, err, nil)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := liquidclient.New(srv.URL, liquidclient.Opts{
+		GetToken: func(context.Context) (string, error) { return "secret-token", nil },
+	})
+	assert.ErrEqual(t, err, nil)
+	return h, c
+}
+
+func TestHandlerHappyPath(t *testing.T) {
+	ctx := t.Context()
+	logic := &testLogic{Version: 42, Quota: map[liquid.ProjectUUID]uint64{projectUUID: 10}}
+	_, c := setupTest(t, logic, Opts{})
+
+	info, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, info.Version, 42)
+
+	capaReport, err := c.GetCapacityReport(ctx, liquid.ServiceCapacityRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, capaReport.Resources["things"].PerAZ[liquid.AvailabilityZoneAny].Capacity, 100)
+
+	err = c.PutQuota(ctx, projectUUID, liquid.ServiceQuotaRequest{
+		Resources: map[liquid.ResourceName]liquid.ResourceQuotaRequest{"things": {Quota: 20}},
+	})
+	assert.ErrEqual(t, err, nil)
+
+	usageReport, err := c.GetUsageReport(ctx, projectUUID, liquid.ServiceUsageRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, usageReport.Resources["things"].Quota, Some[int64](20))
+
+	resp, err := c.ChangeCommitments(ctx, liquid.CommitmentChangeRequest{AZ: "az-one", InfoVersion: 42})
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, resp.RejectionReason, "not today")
+}
+
+func TestHandlerRejectsInvalidCommitmentChange(t *testing.T) {
+	ctx := t.Context()
+	logic := &testLogic{Version: 42}
+	_, c := setupTest(t, logic, Opts{})
+
+	_, err := c.ChangeCommitments(ctx, liquid.CommitmentChangeRequest{AZ: "az-one", InfoVersion: 41})
+	assert.ErrEqual(t, err, "POST /v1/change-commitments returned 400 Bad Request: "+
+		"received CommitmentChangeRequest is invalid: expected .InfoVersion = 42, but got 41")
+}
+
+func TestHandlerErrors(t *testing.T) {
+	ctx := t.Context()
+	logic := &testLogic{Version: 42, Quota: map[liquid.ProjectUUID]uint64{projectUUID: 10}}
+	h, c := setupTest(t, logic, Opts{})
+
+	// unknown project (error from Logic with explicit status code)
+	_, err := c.GetUsageReport(ctx, projectUUID2, liquid.ServiceUsageRequest{})
+	assert.ErrEqual(t, err, "POST /v1/projects/"+projectUUID2+"/report-usage returned 404 Not Found: no such project")
+
+	// malformed project UUID
+	_, err = c.GetUsageReport(ctx, "not-a-uuid", liquid.ServiceUsageRequest{})
+	assert.ErrEqual(t, err, `POST /v1/projects/not-a-uuid/report-usage returned 400 Bad Request: malformed project UUID: "not-a-uuid"`)
+
+	// project metadata does not match path
+	err = c.PutQuota(ctx, projectUUID, liquid.ServiceQuotaRequest{
+		ProjectMetadata: Some(liquid.ProjectMetadata{UUID: projectUUID2}),
+	})
+	assert.ErrEqual(t, err, "PUT /v1/projects/"+projectUUID+"/quota returned 400 Bad Request: "+
+		`project UUID in request path ("`+projectUUID+`") does not match project UUID in request body ("`+projectUUID2+`")`)
+
+	// malformed request body
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequestWithContext(ctx, http.MethodPost, "/v1/report-capacity", strings.NewReader(`{"allAZs":42}`)))
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+	assert.Equal(t, strings.HasPrefix(rec.Body.String(), "malformed request body: "), true)
+
+	// wrong method
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequestWithContext(ctx, http.MethodDelete, "/v1/info", http.NoBody))
+	assert.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+
+	// invalid report from Logic is not sent out
+	logic.BrokenUsage = true
+	_, err = c.GetUsageReport(ctx, projectUUID, liquid.ServiceUsageRequest{AllAZs: []liquid.AvailabilityZone{"az-one"}})
+	assert.ErrEqual(t, err, "POST /v1/projects/"+projectUUID+"/report-usage returned 500 Internal Server Error: "+
+		`could not produce a valid usage report: received ServiceUsageReport is invalid: .Resources["things"] has no quota reported on resource level, which is invalid for HasQuota = true and topology "flat"`)
+	assert.Equal(t, errors.Is(err, liquidclient.ErrServerSide), true)
+}
+
+func TestHandlerAuthorization(t *testing.T) {
+	ctx := t.Context()
+	logic := &testLogic{Version: 42}
+	_, c := setupTest(t, logic, Opts{
+		Authorize: func(r *http.Request) error {
+			switch r.Header.Get("X-Auth-Token") {
+			case "":
+				return HTTPError{http.StatusUnauthorized, "no token given"}
+			case "secret-token":
+				return HTTPError{http.StatusForbidden, "insufficient access"}
+			default:
+				return nil
+			}
+		},
+	})
+
+	_, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, "GET /v1/info returned 403 Forbidden: insufficient access")
+	assert.Equal(t, errors.Is(err, liquidclient.ErrForbidden), true)
+}
+
+func TestHandlerReloadServiceInfo(t *testing.T) {
+	ctx := t.Context()
+	logic := &testLogic{Version: 42}
+	h, c := setupTest(t, logic, Opts{})
+
+	logic.Version = 43
+	assert.Equal(t, h.ServiceInfo().Version, 42)
+	assert.ErrEqual(t, h.ReloadServiceInfo(ctx), nil)
+	assert.Equal(t, h.ServiceInfo().Version, 43)
+
+	info, err := c.GetInfo(ctx)
+	assert.ErrEqual(t, err, nil)
+	assert.Equal(t, info.Version, 43)
+}
@@ -4,11 +4,18 @@
 package liquid
 
 import (
+	"errors"
 	"fmt"
 	"maps"
+	"math"
+	"math/bits"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	. "go.xyrillian.de/gg/option"
 
 	"github.com/sapcc/go-api-declarations/internal/errorset"
 )
@@ -25,6 +32,10 @@ func isValidIdentifier[S ~string](s S) bool {
 //   - Each resource and rate must have a valid name, according to ResourceName.IsValid() and RateName.IsValid().
 //   - Each resource is declared with a valid topology.
 //   - Each rate is declared with a valid topology.
+//   - Each metric family is declared with a valid type.
+//   - Metric families of type histogram or gaugehistogram must declare the label "le".
+//   - Metric families of type summary must declare the label "quantile".
+//   - Metric families of type stateset must declare a label with the same name as the metric family.
 //
 // Additional validations may be added in the future.
 func ValidateServiceInfo(srv ServiceInfo) error {
@@ -81,6 +92,9 @@ func validateServiceInfoImpl(srv ServiceInfo) (errs errorset.ErrorSet) {
 		}
 	}
 
+	errs.Append(validateMetricFamilies(srv.CapacityMetricFamilies, ".CapacityMetricFamilies"))
+	errs.Append(validateMetricFamilies(srv.UsageMetricFamilies, ".UsageMetricFamilies"))
+
 	for categoryName, categoryInfo := range srv.Categories {
 		if !categoryName.IsValid() {
 			errs.Addf(".Categories[%q] has invalid identifier", categoryName)
@@ -107,6 +121,11 @@ func validateServiceInfoImpl(srv ServiceInfo) (errs errorset.ErrorSet) {
 //     For other topologies, all AZs in req.AllAZs must be present (and possibly AvailabilityZoneUnknown, but no others).
 //   - All metrics families declared in info.CapacityMetricFamilies must be present (and no others).
 //   - The number of labels on each metric must match the declared label set.
+//   - Each metric must have a value that is acceptable for the declared metric type:
+//     Counters must be non-negative, infos must be 1, and statesets must be 0 or 1.
+//   - For histograms and gaugehistograms, the "le" label of each metric must be a number,
+//     and the buckets for each label set must be listed in ascending order, be cumulative, and end with le="+Inf".
+//   - For summaries, the "quantile" label of each metric must be a number between 0 and 1.
 //
 // Additional validations may be added in the future.
 func ValidateCapacityReport(report ServiceCapacityReport, req ServiceCapacityRequest, info ServiceInfo) error {
@@ -171,6 +190,11 @@ func validateCapacityReportImpl(report ServiceCapacityReport, req ServiceCapacit
 //   - For rate usage values, the value Some(nil) is forbidden.
 //   - All metrics families declared in info.UsageMetricFamilies must be present (and no others).
 //   - The number of labels on each metric must match the declared label set.
+//   - Each metric must have a value that is acceptable for the declared metric type:
+//     Counters must be non-negative, infos must be 1, and statesets must be 0 or 1.
+//   - For histograms and gaugehistograms, the "le" label of each metric must be a number,
+//     and the buckets for each label set must be listed in ascending order, be cumulative, and end with le="+Inf".
+//   - For summaries, the "quantile" label of each metric must be a number between 0 and 1.
 //
 // Additional validations may be added in the future.
 func ValidateUsageReport(report ServiceUsageReport, req ServiceUsageRequest, info ServiceInfo) error {
@@ -238,6 +262,151 @@ func validateUsageReportImpl(report ServiceUsageReport, req ServiceUsageRequest,
 	return errs
 }
 
+// ValidateCommitmentChangeRequest checks that the provided request is consistent with the provided ServiceInfo.
+// Currently, this means that:
+//
+//   - The req.InfoVersion must match the value in info.Version.
+//     (This is a hard error here. As per the documentation on CommitmentChangeRequest.InfoVersion, the liquid shall reject requests with a different version.)
+//   - All resources mentioned in the request must be declared in info.Resources with HandlesCommitments = true.
+//   - The req.AZ must be consistent with the declared topology of each resource:
+//     For FlatTopology, only AvailabilityZoneAny is allowed.
+//     For other topologies, req.AZ must be a real AZ (as per AvailabilityZone.IsReal()).
+//   - For each commitment, OldStatus and NewStatus must describe a valid status transition as per func CommitmentStatusTransitions.
+//   - For each commitment, ConfirmBy (if any) must be before ExpiresAt.
+//   - For each resource, the TotalConfirmedBefore/After and TotalGuaranteedBefore/After values must be consistent with the commitments in the changeset:
+//     The difference between the Before and After values must be equal to the sum of the amounts of the commitments entering and leaving the respective status.
+//
+// Additional validations may be added in the future.
+func ValidateCommitmentChangeRequest(req CommitmentChangeRequest, info ServiceInfo) error {
+	errs := validateCommitmentChangeRequestImpl(req, info)
+	if len(errs) > 0 {
+		// NOTE: Errors get joined with "; " instead of ", " because some errors contain commas themselves.
+		return fmt.Errorf("received CommitmentChangeRequest is invalid: %s", errs.Join("; "))
+	}
+	return nil
+}
+
+// This is the function that the unit tests call. An ErrorSet is easier to compare against fixtures than the final stringified error.
+func validateCommitmentChangeRequestImpl(req CommitmentChangeRequest, info ServiceInfo) (errs errorset.ErrorSet) {
+	if req.InfoVersion != info.Version {
+		errs.Addf("expected .InfoVersion = %d, but got %d", info.Version, req.InfoVersion)
+		// assume that all other errors would be aftereffects of the version mismatch, and skip finding them
+		return errs
+	}
+
+	for _, projectUUID := range slices.Sorted(maps.Keys(req.ByProject)) {
+		pc := req.ByProject[projectUUID]
+		for _, resName := range slices.Sorted(maps.Keys(pc.ByResource)) {
+			rc := pc.ByResource[resName]
+			path := fmt.Sprintf(".ByProject[%q].ByResource[%q]", projectUUID, resName)
+
+			resInfo, exists := info.Resources[resName]
+			if !exists {
+				errs.Addf("unexpected value for %s (resource was not declared)", path)
+				continue
+			}
+			if !resInfo.HandlesCommitments {
+				errs.Addf("unexpected value for %s (resource was declared with HandlesCommitments = false)", path)
+				continue
+			}
+			switch {
+			case resInfo.Topology == FlatTopology && req.AZ != AvailabilityZoneAny:
+				errs.Addf("invalid value for .AZ: %q is not acceptable for %s with topology %q (expected %q)", req.AZ, path, resInfo.Topology, AvailabilityZoneAny)
+			case resInfo.Topology != FlatTopology && !req.AZ.IsReal():
+				errs.Addf("invalid value for .AZ: %q is not acceptable for %s with topology %q (expected a real AZ)", req.AZ, path, resInfo.Topology)
+			}
+
+			errs.Append(validateResourceCommitmentChangeset(rc, path))
+		}
+	}
+
+	return errs
+}
+
+func validateResourceCommitmentChangeset(rc ResourceCommitmentChangeset, path string) (errs errorset.ErrorSet) {
+	// NOTE: Like in RequiresConfirmation(), this algorithm is purposefully written to never use subtractions.
+	var (
+		confirmedBefore, confirmedAfter   uint64
+		guaranteedBefore, guaranteedAfter uint64
+		confirmedOverflow                 bool
+		guaranteedOverflow                bool
+	)
+	addAmount := func(sum *uint64, overflow *bool, amount uint64) {
+		var carry uint64
+		*sum, carry = bits.Add64(*sum, amount, 0)
+		*overflow = *overflow || carry != 0
+	}
+	for idx, c := range rc.Commitments {
+		cpath := fmt.Sprintf("%s.Commitments[%d]", path, idx)
+		err := validateCommitmentStatusTransition(c.OldStatus, c.NewStatus)
+		if err != nil {
+			errs.Addf("invalid status transition for %s: %w", cpath, err)
+		}
+		confirmBy, ok := c.ConfirmBy.Unpack()
+		if ok && !confirmBy.Before(c.ExpiresAt) {
+			errs.Addf("invalid value for %s.ConfirmBy: %s is not before ExpiresAt = %s",
+				cpath, confirmBy.Format(time.RFC3339), c.ExpiresAt.Format(time.RFC3339))
+		}
+
+		switch c.OldStatus {
+		case Some(CommitmentStatusConfirmed):
+			addAmount(&confirmedBefore, &confirmedOverflow, c.Amount)
+		case Some(CommitmentStatusGuaranteed):
+			addAmount(&guaranteedBefore, &guaranteedOverflow, c.Amount)
+		}
+		switch c.NewStatus {
+		case Some(CommitmentStatusConfirmed):
+			addAmount(&confirmedAfter, &confirmedOverflow, c.Amount)
+		case Some(CommitmentStatusGuaranteed):
+			addAmount(&guaranteedAfter, &guaranteedOverflow, c.Amount)
+		}
+	}
+
+	// The totals include commitments not listed in the changeset, so we can only check that
+	// (1) the listed commitments fit into the totals, and
+	// (2) the untouched remainder is the same before and after.
+	// Once (1) holds, the sums in (2) may wrap around, but the comparison is still exact
+	// since both remainders are in the range of uint64.
+	checkTotals := func(field string, totalBefore, totalAfter, listedBefore, listedAfter uint64, overflow bool) {
+		if overflow {
+			errs.Addf("invalid values for %s.Commitments: total amount of %s commitments does not fit into uint64",
+				path, strings.ToLower(field))
+			return
+		}
+		if totalBefore < listedBefore || totalAfter < listedAfter || totalBefore+listedAfter != totalAfter+listedBefore {
+			errs.Addf("inconsistent values for %s.Total%sBefore = %d and %s.Total%sAfter = %d (listed commitments account for %d before and %d after)",
+				path, field, totalBefore, path, field, totalAfter, listedBefore, listedAfter)
+		}
+	}
+	checkTotals("Confirmed", rc.TotalConfirmedBefore, rc.TotalConfirmedAfter, confirmedBefore, confirmedAfter, confirmedOverflow)
+	checkTotals("Guaranteed", rc.TotalGuaranteedBefore, rc.TotalGuaranteedAfter, guaranteedBefore, guaranteedAfter, guaranteedOverflow)
+
+	return errs
+}
+
+func validateCommitmentStatusTransition(oldStatus, newStatus Option[CommitmentStatus]) error {
+	for _, s := range []Option[CommitmentStatus]{oldStatus, newStatus} {
+		if status, ok := s.Unpack(); ok && !status.IsValid() {
+			return fmt.Errorf("%q is not a valid CommitmentStatus", status)
+		}
+	}
+	if oldStatus.IsNone() && newStatus.IsNone() {
+		return errors.New("OldStatus and NewStatus cannot both be None")
+	}
+	if _, ok := FindCommitmentStatusTransition(oldStatus, newStatus); !ok {
+		return fmt.Errorf("cannot go from %s to %s", formatCommitmentStatus(oldStatus), formatCommitmentStatus(newStatus))
+	}
+	return nil
+}
+
+func formatCommitmentStatus(s Option[CommitmentStatus]) string {
+	status, ok := s.Unpack()
+	if !ok {
+		return "None"
+	}
+	return fmt.Sprintf("%q", status)
+}
+
 func validatePerAZAgainstTopology[N ~string, V any](perAZ map[AvailabilityZone]V, topology Topology, path string, name N, allAZs []AvailabilityZone) error {
 	// this is specifically written to blow up when we add new topologies
 	// and forget to update this function accordingly
@@ -350,14 +519,138 @@ func validateMetrics(allMetrics map[MetricName][]Metric, families map[MetricName
 			errs.Addf("unexpected value for .Metrics[%q] (not declared in %s)", familyName, path)
 			continue
 		}
+		hasMalformedLabels := false
 		for idx, metric := range metrics {
 			if len(metric.LabelValues) != len(familyInfo.LabelKeys) {
 				errs.Addf("malformed value for .Metrics[%q][%d].LabelValues (expected %d, but got %d entries)",
 					familyName, idx, len(familyInfo.LabelKeys), len(metric.LabelValues))
+				hasMalformedLabels = true
+			}
+		}
+		if !hasMalformedLabels {
+			errs.Append(validateMetricsOfFamily(familyName, familyInfo, metrics))
+		}
+	}
+
+	return errs
+}
+
+func validateMetricFamilies(families map[MetricName]MetricFamilyInfo, path string) (errs errorset.ErrorSet) {
+	for _, familyName := range slices.Sorted(maps.Keys(families)) {
+		familyInfo := families[familyName]
+		requiredLabel := ""
+		switch familyInfo.Type {
+		case MetricTypeHistogram, MetricTypeGaugeHistogram:
+			requiredLabel = "le"
+		case MetricTypeSummary:
+			requiredLabel = "quantile"
+		case MetricTypeStateset:
+			requiredLabel = string(familyName)
+		default:
+			if !familyInfo.Type.IsValid() {
+				errs.Addf("%s[%q] has invalid type %q", path, familyName, familyInfo.Type)
+			}
+		}
+		if requiredLabel != "" && !slices.Contains(familyInfo.LabelKeys, requiredLabel) {
+			errs.Addf("%s[%q] has type %q, but does not declare the label %q", path, familyName, familyInfo.Type, requiredLabel)
+		}
+	}
+	return errs
+}
+
+// This function assumes that the number of label values has already been validated.
+func validateMetricsOfFamily(familyName MetricName, familyInfo MetricFamilyInfo, metrics []Metric) (errs errorset.ErrorSet) {
+	switch familyInfo.Type {
+	case MetricTypeCounter:
+		for idx, metric := range metrics {
+			if !(metric.Value >= 0) {
+				errs.Addf("invalid value for .Metrics[%q][%d].Value (expected a non-negative number for type %q, but got %g)",
+					familyName, idx, familyInfo.Type, metric.Value)
+			}
+		}
+	case MetricTypeInfo:
+		for idx, metric := range metrics {
+			if metric.Value != 1 {
+				errs.Addf("invalid value for .Metrics[%q][%d].Value (expected 1 for type %q, but got %g)",
+					familyName, idx, familyInfo.Type, metric.Value)
 			}
 		}
+	case MetricTypeStateset:
+		for idx, metric := range metrics {
+			if metric.Value != 0 && metric.Value != 1 {
+				errs.Addf("invalid value for .Metrics[%q][%d].Value (expected 0 or 1 for type %q, but got %g)",
+					familyName, idx, familyInfo.Type, metric.Value)
+			}
+		}
+	case MetricTypeHistogram, MetricTypeGaugeHistogram:
+		errs.Append(validateHistogramBuckets(familyName, familyInfo, metrics))
+	case MetricTypeSummary:
+		quantileIndex := slices.Index(familyInfo.LabelKeys, "quantile")
+		if quantileIndex < 0 {
+			return errs // this is reported by ValidateServiceInfo
+		}
+		for idx, metric := range metrics {
+			quantileStr := metric.LabelValues[quantileIndex]
+			quantile, err := strconv.ParseFloat(quantileStr, 64)
+			if err != nil || !(quantile >= 0 && quantile <= 1) {
+				errs.Addf("invalid value for .Metrics[%q][%d].LabelValues (expected quantile between 0 and 1, but got %q)",
+					familyName, idx, quantileStr)
+			}
+		}
+	}
+	return errs
+}
+
+func validateHistogramBuckets(familyName MetricName, familyInfo MetricFamilyInfo, metrics []Metric) (errs errorset.ErrorSet) {
+	leIndex := slices.Index(familyInfo.LabelKeys, "le")
+	if leIndex < 0 {
+		return errs // this is reported by ValidateServiceInfo
 	}
 
+	// buckets are grouped by all labels except for "le"
+	type bucketGroup struct {
+		LastIndex int
+		LastLE    float64
+		LastValue float64
+	}
+	var groupKeys []string
+	groups := make(map[string]*bucketGroup)
+	for idx, metric := range metrics {
+		if !(metric.Value >= 0) {
+			errs.Addf("invalid value for .Metrics[%q][%d].Value (expected a non-negative number for type %q, but got %g)",
+				familyName, idx, familyInfo.Type, metric.Value)
+		}
+		leStr := metric.LabelValues[leIndex]
+		le, err := strconv.ParseFloat(leStr, 64)
+		if err != nil || math.IsNaN(le) {
+			errs.Addf("invalid value for .Metrics[%q][%d].LabelValues (expected a number for label \"le\", but got %q)",
+				familyName, idx, leStr)
+			continue
+		}
+
+		groupKey := strings.Join(slices.Delete(slices.Clone(metric.LabelValues), leIndex, leIndex+1), "\xff")
+		group, exists := groups[groupKey]
+		if !exists {
+			groups[groupKey] = &bucketGroup{LastIndex: idx, LastLE: le, LastValue: metric.Value}
+			groupKeys = append(groupKeys, groupKey)
+			continue
+		}
+		if le <= group.LastLE {
+			errs.Addf("invalid value for .Metrics[%q][%d].LabelValues (bucket le=%q is not in ascending order after the bucket in .Metrics[%[1]q][%[4]d])",
+				familyName, idx, leStr, group.LastIndex)
+		} else if metric.Value < group.LastValue {
+			errs.Addf("invalid value for .Metrics[%q][%d].Value (bucket le=%q has a lower count than the bucket in .Metrics[%[1]q][%[4]d], but buckets must be cumulative)",
+				familyName, idx, leStr, group.LastIndex)
+		}
+		*group = bucketGroup{LastIndex: idx, LastLE: le, LastValue: metric.Value}
+	}
+
+	for _, groupKey := range groupKeys {
+		group := groups[groupKey]
+		if !math.IsInf(group.LastLE, +1) {
+			errs.Addf("missing bucket with le=\"+Inf\" after .Metrics[%q][%d]", familyName, group.LastIndex)
+		}
+	}
 	return errs
 }
 
@@ -4,10 +4,12 @@
 package liquid
 
 import (
+	"math"
 	"math/big"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	. "go.xyrillian.de/gg/option"
 
@@ -136,6 +138,15 @@ func TestValidateServiceInfo(t *testing.T) {
 			"bla1":       {Category: Some(CategoryName("")), HasUsage: true, Topology: FlatTopology},                    // Invalid category
 			"bla2":       {Category: Some(CategoryName("someUnknownCategory")), HasUsage: true, Topology: FlatTopology}, // Unknown category
 		},
+		CapacityMetricFamilies: map[MetricName]MetricFamilyInfo{
+			"weird_metric":     {Type: "weird"},                                    // Invalid type
+			"latency_seconds":  {Type: MetricTypeHistogram, LabelKeys: []string{}}, // Missing "le" label
+			"response_seconds": {Type: MetricTypeSummary, LabelKeys: []string{"le"}},
+		},
+		UsageMetricFamilies: map[MetricName]MetricFamilyInfo{
+			"power_state": {Type: MetricTypeStateset, LabelKeys: []string{"state"}}, // Missing label named like the family
+			"queue_size":  {Type: MetricTypeGaugeHistogram, LabelKeys: []string{"le"}},
+		},
 	}
 	expectedErrStrings := []string{
 		`.Resources["foo"] has invalid topology ""`,
@@ -151,6 +162,10 @@ func TestValidateServiceInfo(t *testing.T) {
 		`.Categories[""] has invalid identifier`,
 		`.Categories["extra"] is not referenced by any resource or rate`,
 		`.Categories["empty"] has invalid DisplayName`,
+		`.CapacityMetricFamilies["weird_metric"] has invalid type "weird"`,
+		`.CapacityMetricFamilies["latency_seconds"] has type "histogram", but does not declare the label "le"`,
+		`.CapacityMetricFamilies["response_seconds"] has type "summary", but does not declare the label "quantile"`,
+		`.UsageMetricFamilies["power_state"] has type "stateset", but does not declare the label "power_state"`,
 	}
 	errs := validateServiceInfoImpl(invalidServiceInfo)
 	assertErrorSet(t, errs, expectedErrStrings)
@@ -508,6 +523,254 @@ func TestValidateUsageReport(t *testing.T) {
 	}
 }
 
+func TestValidateCommitmentChangeRequest(t *testing.T) {
+	info := serviceInfo.Clone()
+	for _, resName := range []ResourceName{"foo", "bar", "qux"} {
+		resInfo := info.Resources[resName]
+		resInfo.HandlesCommitments = true
+		info.Resources[resName] = resInfo
+	}
+
+	// InfoVersion mismatch is reported as the only error
+	errs := validateCommitmentChangeRequestImpl(CommitmentChangeRequest{InfoVersion: 409}, info)
+	assertErrorSet(t, errs, []string{`expected .InfoVersion = 73, but got 409`})
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	oneYearLater := now.AddDate(1, 0, 0)
+
+	invalidRequest := CommitmentChangeRequest{
+		AZ:          "az-one",
+		InfoVersion: 73,
+		ByProject: map[ProjectUUID]ProjectCommitmentChangeset{
+			"uuid-for-dresden": {
+				ByResource: map[ResourceName]ResourceCommitmentChangeset{
+					// not declared
+					"unknown": {},
+					// declared with HandlesCommitments = false
+					"baz": {},
+					// flat topology does not accept a real AZ
+					"bar": {},
+					// sum of listed commitments overflows
+					"qux": {
+						TotalConfirmedBefore: 0,
+						TotalConfirmedAfter:  math.MaxUint64,
+						Commitments: []Commitment{
+							{
+								UUID:      "uuid-7",
+								NewStatus: Some(CommitmentStatusConfirmed),
+								Amount:    math.MaxUint64/2 + 1,
+								ExpiresAt: oneYearLater,
+							},
+							{
+								UUID:      "uuid-8",
+								NewStatus: Some(CommitmentStatusConfirmed),
+								Amount:    math.MaxUint64/2 + 1,
+								ExpiresAt: oneYearLater,
+							},
+						},
+					},
+					"foo": {
+						// 10 + 5 - 3 != 13
+						TotalConfirmedBefore: 10,
+						TotalConfirmedAfter:  13,
+						// cannot have removed more than was there before
+						TotalGuaranteedBefore: 2,
+						TotalGuaranteedAfter:  0,
+						Commitments: []Commitment{
+							{
+								UUID:      "uuid-1",
+								NewStatus: Some(CommitmentStatusConfirmed),
+								Amount:    5,
+								ExpiresAt: oneYearLater,
+							},
+							{
+								UUID:      "uuid-2",
+								OldStatus: Some(CommitmentStatusConfirmed),
+								NewStatus: Some(CommitmentStatusExpired),
+								Amount:    3,
+								ExpiresAt: now,
+							},
+							{
+								UUID:      "uuid-3",
+								OldStatus: Some(CommitmentStatusGuaranteed),
+								Amount:    4,
+								ConfirmBy: Some(oneYearLater),
+								ExpiresAt: oneYearLater,
+							},
+							{
+								UUID:      "uuid-4",
+								OldStatus: Some(CommitmentStatusExpired),
+								NewStatus: Some(CommitmentStatusConfirmed),
+								Amount:    0,
+								ExpiresAt: oneYearLater,
+							},
+							{
+								UUID:      "uuid-5",
+								Amount:    0,
+								ExpiresAt: oneYearLater,
+							},
+							{
+								UUID:      "uuid-6",
+								NewStatus: Some(CommitmentStatus("weird")),
+								Amount:    0,
+								ExpiresAt: oneYearLater,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	const fooPath = `.ByProject["uuid-for-dresden"].ByResource["foo"]`
+	expectedErrStrings := []string{
+		`unexpected value for .ByProject["uuid-for-dresden"].ByResource["unknown"] (resource was not declared)`,
+		`unexpected value for .ByProject["uuid-for-dresden"].ByResource["baz"] (resource was declared with HandlesCommitments = false)`,
+		`invalid value for .AZ: "az-one" is not acceptable for .ByProject["uuid-for-dresden"].ByResource["bar"] with topology "flat" (expected "any")`,
+		`invalid value for ` + fooPath + `.Commitments[2].ConfirmBy: 2027-01-01T00:00:00Z is not before ExpiresAt = 2027-01-01T00:00:00Z`,
+		`invalid status transition for ` + fooPath + `.Commitments[3]: cannot go from "expired" to "confirmed"`,
+		`invalid status transition for ` + fooPath + `.Commitments[4]: OldStatus and NewStatus cannot both be None`,
+		`invalid status transition for ` + fooPath + `.Commitments[5]: "weird" is not a valid CommitmentStatus`,
+		`inconsistent values for ` + fooPath + `.TotalConfirmedBefore = 10 and ` + fooPath + `.TotalConfirmedAfter = 13 (listed commitments account for 3 before and 5 after)`,
+		`inconsistent values for ` + fooPath + `.TotalGuaranteedBefore = 2 and ` + fooPath + `.TotalGuaranteedAfter = 0 (listed commitments account for 4 before and 0 after)`,
+		`invalid values for .ByProject["uuid-for-dresden"].ByResource["qux"].Commitments: total amount of confirmed commitments does not fit into uint64`,
+	}
+	errs = validateCommitmentChangeRequestImpl(invalidRequest, info)
+	assertErrorSet(t, errs, expectedErrStrings)
+
+	// AZ-aware resources do not accept non-real AZs
+	invalidRequest2 := CommitmentChangeRequest{
+		AZ:          AvailabilityZoneAny,
+		InfoVersion: 73,
+		ByProject: map[ProjectUUID]ProjectCommitmentChangeset{
+			"uuid-for-dresden": {
+				ByResource: map[ResourceName]ResourceCommitmentChangeset{
+					"qux": {},
+				},
+			},
+		},
+	}
+	errs = validateCommitmentChangeRequestImpl(invalidRequest2, info)
+	assertErrorSet(t, errs, []string{
+		`invalid value for .AZ: "any" is not acceptable for .ByProject["uuid-for-dresden"].ByResource["qux"] with topology "az-separated" (expected a real AZ)`,
+	})
+
+	// a valid request that moves a commitment between projects and confirms a guaranteed commitment
+	validRequest := CommitmentChangeRequest{
+		AZ:          "az-one",
+		InfoVersion: 73,
+		ByProject: map[ProjectUUID]ProjectCommitmentChangeset{
+			"uuid-for-dresden": {
+				ByResource: map[ResourceName]ResourceCommitmentChangeset{
+					"foo": {
+						TotalConfirmedBefore:  15,
+						TotalConfirmedAfter:   12,
+						TotalGuaranteedBefore: 4,
+						TotalGuaranteedAfter:  0,
+						Commitments: []Commitment{
+							{
+								UUID:      "uuid-1",
+								OldStatus: Some(CommitmentStatusConfirmed),
+								Amount:    7,
+								ExpiresAt: oneYearLater,
+							},
+							{
+								UUID:      "uuid-2",
+								OldStatus: Some(CommitmentStatusGuaranteed),
+								NewStatus: Some(CommitmentStatusConfirmed),
+								Amount:    4,
+								ConfirmBy: Some(now),
+								ExpiresAt: oneYearLater,
+							},
+						},
+					},
+				},
+			},
+			"uuid-for-berlin": {
+				ByResource: map[ResourceName]ResourceCommitmentChangeset{
+					"foo": {
+						TotalConfirmedBefore: 0,
+						TotalConfirmedAfter:  7,
+						Commitments: []Commitment{
+							{
+								UUID:      "uuid-1",
+								NewStatus: Some(CommitmentStatusConfirmed),
+								Amount:    7,
+								ExpiresAt: oneYearLater,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	errs = validateCommitmentChangeRequestImpl(validRequest, info)
+	if !errs.IsEmpty() {
+		t.Errorf("expected no errors for a valid CommitmentChangeRequest but got: %s", errs.Join(", "))
+	}
+}
+
+func TestValidateMetricsByType(t *testing.T) {
+	families := map[MetricName]MetricFamilyInfo{
+		"requests_total":   {Type: MetricTypeCounter, LabelKeys: []string{"method"}},
+		"build_info":       {Type: MetricTypeInfo, LabelKeys: []string{"version"}},
+		"power_state":      {Type: MetricTypeStateset, LabelKeys: []string{"power_state"}},
+		"latency_seconds":  {Type: MetricTypeHistogram, LabelKeys: []string{"method", "le"}},
+		"queue_size":       {Type: MetricTypeGaugeHistogram, LabelKeys: []string{"le"}},
+		"response_seconds": {Type: MetricTypeSummary, LabelKeys: []string{"quantile"}},
+		"temperature":      {Type: MetricTypeGauge, LabelKeys: []string{}},
+	}
+
+	validMetrics := map[MetricName][]Metric{
+		"requests_total": {{Value: 0, LabelValues: []string{"GET"}}, {Value: 42, LabelValues: []string{"PUT"}}},
+		"build_info":     {{Value: 1, LabelValues: []string{"1.2.3"}}},
+		"power_state":    {{Value: 1, LabelValues: []string{"on"}}, {Value: 0, LabelValues: []string{"off"}}},
+		"latency_seconds": {
+			{Value: 3, LabelValues: []string{"GET", "0.5"}},
+			{Value: 0, LabelValues: []string{"PUT", "0.5"}},
+			{Value: 5, LabelValues: []string{"GET", "+Inf"}},
+			{Value: 1, LabelValues: []string{"PUT", "+Inf"}},
+		},
+		"queue_size":       {{Value: 2, LabelValues: []string{"1e3"}}, {Value: 2, LabelValues: []string{"+Inf"}}},
+		"response_seconds": {{Value: -3, LabelValues: []string{"0"}}, {Value: 7, LabelValues: []string{"1"}}},
+		"temperature":      {{Value: -273, LabelValues: []string{}}},
+	}
+	errs := validateMetrics(validMetrics, families, ".UsageMetricFamilies")
+	if !errs.IsEmpty() {
+		t.Errorf("expected no errors for valid metrics but got: %s", errs.Join(", "))
+	}
+
+	invalidMetrics := map[MetricName][]Metric{
+		"requests_total": {{Value: -1, LabelValues: []string{"GET"}}},
+		"build_info":     {{Value: 2, LabelValues: []string{"1.2.3"}}},
+		"power_state":    {{Value: 0.5, LabelValues: []string{"on"}}},
+		"latency_seconds": {
+			{Value: 3, LabelValues: []string{"GET", "0.5"}},
+			{Value: 2, LabelValues: []string{"GET", "1"}},      // not cumulative
+			{Value: 4, LabelValues: []string{"GET", "0.7"}},    // not ascending
+			{Value: 0, LabelValues: []string{"PUT", "+Inf"}},   // separate group, is fine
+			{Value: 5, LabelValues: []string{"POST", "large"}}, // not a number
+		},
+		"queue_size":       {{Value: -2, LabelValues: []string{"10"}}}, // negative, and missing +Inf bucket
+		"response_seconds": {{Value: 3, LabelValues: []string{"1.5"}}, {Value: 3, LabelValues: []string{"NaN"}}},
+		"temperature":      {{Value: 1, LabelValues: []string{"foo"}}}, // not checked further since label count is wrong
+	}
+	errs = validateMetrics(invalidMetrics, families, ".UsageMetricFamilies")
+	assertErrorSet(t, errs, []string{
+		`invalid value for .Metrics["requests_total"][0].Value (expected a non-negative number for type "counter", but got -1)`,
+		`invalid value for .Metrics["build_info"][0].Value (expected 1 for type "info", but got 2)`,
+		`invalid value for .Metrics["power_state"][0].Value (expected 0 or 1 for type "stateset", but got 0.5)`,
+		`invalid value for .Metrics["latency_seconds"][1].Value (bucket le="1" has a lower count than the bucket in .Metrics["latency_seconds"][0], but buckets must be cumulative)`,
+		`invalid value for .Metrics["latency_seconds"][2].LabelValues (bucket le="0.7" is not in ascending order after the bucket in .Metrics["latency_seconds"][1])`,
+		`invalid value for .Metrics["latency_seconds"][4].LabelValues (expected a number for label "le", but got "large")`,
+		`missing bucket with le="+Inf" after .Metrics["latency_seconds"][2]`,
+		`invalid value for .Metrics["queue_size"][0].Value (expected a non-negative number for type "gaugehistogram", but got -2)`,
+		`missing bucket with le="+Inf" after .Metrics["queue_size"][0]`,
+		`invalid value for .Metrics["response_seconds"][0].LabelValues (expected quantile between 0 and 1, but got "1.5")`,
+		`invalid value for .Metrics["response_seconds"][1].LabelValues (expected quantile between 0 and 1, but got "NaN")`,
+		`malformed value for .Metrics["temperature"][0].LabelValues (expected 0, but got 1 entries)`,
+	})
+}
+
 func assertErrorSet(t *testing.T, actualErrorSet errorset.ErrorSet, expectedErrStrings []string) {
 	t.Helper()
 